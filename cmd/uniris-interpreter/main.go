@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -24,35 +25,120 @@ func main() {
 			Name:  "file, f",
 			Usage: "Interpret from a `FILE` source code",
 		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "Compile the source code into an artifact written in `FILE` instead of interpreting it",
+		},
+		cli.StringFlag{
+			Name:  "artifact",
+			Usage: "Run a compiled contract `FILE` artifact",
+		},
 		cli.BoolFlag{
 			Name:  "console",
 			Usage: "Open console to interpret code instantly",
 		},
+		cli.StringFlag{
+			Name:  "account",
+			Value: "contract",
+			Usage: "`ADDRESS` of the contract account spending IRIS",
+		},
+		cli.StringFlag{
+			Name:  "balance",
+			Value: "0",
+			Usage: "Initial IRIS `AMOUNT` of the contract account in the in-memory ledger",
+		},
+		cli.Uint64Flag{
+			Name:  "gas",
+			Usage: "Maximum `GAS` an execution can use (0 means unlimited)",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "Maximum `DURATION` of an execution (0 means unlimited)",
+		},
+		cli.Int64Flag{
+			Name:  "timestamp",
+			Usage: "Run in deterministic mode with `TIMESTAMP` as current time",
+		},
 	}
 
 	app.Action = func(c *cli.Context) error {
 
-		if c.String("file") != "" {
+		balance, err := uniris.ParseDecimal(c.String("balance"))
+		if err != nil {
+			return err
+		}
+		ledger := uniris.NewMemoryLedger(map[string]uniris.Decimal{
+			c.String("account"): balance,
+		})
+
+		if c.String("file") != "" && c.String("output") != "" {
+			code, err := ioutil.ReadFile(c.String("file"))
+			if err != nil {
+				return err
+			}
+			b, err := uniris.Compile(string(code))
+			if err != nil {
+				fmt.Printf("Error: %s\n", err)
+				return nil
+			}
+			data, err := b.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(c.String("output"), data, 0644); err != nil {
+				return err
+			}
+			address, err := b.Address()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Contract address: %s\n", address)
+			return nil
+		} else if c.String("artifact") != "" {
+			data, err := ioutil.ReadFile(c.String("artifact"))
+			if err != nil {
+				return err
+			}
+			b, err := uniris.LoadBytecode(data)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := executionContext(c)
+			defer cancel()
+			res, err := newInterpreter(c, ledger).RunBytecodeContext(ctx, b)
+			if err != nil {
+				fmt.Printf("Error: %s\n", err)
+				return nil
+			}
+			fmt.Print(res.Output)
+			return nil
+		} else if c.String("file") != "" {
 			code, err := ioutil.ReadFile(c.String("file"))
 			if err != nil {
 				return err
 			}
-			res, err := uniris.Interpret(string(code), nil)
+			ctx, cancel := executionContext(c)
+			defer cancel()
+			res, err := newInterpreter(c, ledger).RunContext(ctx, string(code))
 			if err != nil {
 				fmt.Printf("Error: %s\n", err)
+				return nil
 			}
-			fmt.Print(res)
+			fmt.Print(res.Output)
 			return nil
 		} else if c.Bool("console") {
 			fmt.Println("Type Ctrl-C to exit the console")
-			env := uniris.NewEnvironment(nil)
+			in := newInterpreter(c, ledger)
 			for {
 				text := read()
-				res, err := uniris.Interpret(text, env)
+				ctx, cancel := executionContext(c)
+				res, err := in.RunContext(ctx, text)
+				cancel()
 				if err != nil {
 					fmt.Printf("Error: %s\n", err)
+					continue
 				}
-				fmt.Print(res)
+				fmt.Print(res.Output)
 			}
 		}
 
@@ -66,6 +152,30 @@ func main() {
 
 }
 
+func newInterpreter(c *cli.Context, ledger uniris.Ledger) *uniris.Interpreter {
+	opts := []uniris.Option{
+		uniris.WithLedger(ledger, c.String("account")),
+		uniris.WithGasLimit(c.Uint64("gas")),
+	}
+	if c.IsSet("timestamp") {
+		timestamp := c.Int64("timestamp")
+		opts = append(opts,
+			uniris.WithDeterministic(true),
+			uniris.WithClock(func() int64 {
+				return timestamp
+			}),
+		)
+	}
+	return uniris.NewInterpreter(opts...)
+}
+
+func executionContext(c *cli.Context) (context.Context, context.CancelFunc) {
+	if c.Duration("timeout") > 0 {
+		return context.WithTimeout(context.Background(), c.Duration("timeout"))
+	}
+	return context.WithCancel(context.Background())
+}
+
 func read() string {
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print("> ")
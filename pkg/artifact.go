@@ -0,0 +1,425 @@
+package uniris
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+)
+
+//ArtifactVersion is the version of the binary format of the compiled contracts
+const ArtifactVersion = 1
+
+//artifactMagic starts every compiled contract artifact
+const artifactMagic = "IRIS"
+
+//Tags of the constant pool entries
+const (
+	constInteger byte = iota
+	constFloat
+	constDecimal
+	constString
+	constBoolean
+	constKeys
+	constFunction
+	constContract
+)
+
+var errTruncatedArtifact = errors.New("Invalid artifact: unexpected end of data")
+
+//MarshalBinary encodes the bytecode as a versioned artifact.
+//The encoding is deterministic: the same code always gives the same artifact.
+func (b *Bytecode) MarshalBinary() ([]byte, error) {
+	w := &artifactWriter{}
+	w.buf.WriteString(artifactMagic)
+	w.uint(ArtifactVersion)
+	w.string(b.metadata.Contract)
+	w.strings(b.metadata.Fields)
+	w.strings(b.metadata.Functions)
+	if err := w.chunk(b.main); err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+//Address returns the contract address derived from the content hash of its artifact
+func (b *Bytecode) Address() (string, error) {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+//LoadBytecode decodes an artifact produced by Bytecode.MarshalBinary without parsing the code again
+func LoadBytecode(data []byte) (*Bytecode, error) {
+	b := &Bytecode{}
+	if err := b.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+//UnmarshalBinary decodes an artifact produced by Bytecode.MarshalBinary
+func (b *Bytecode) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(artifactMagic)) {
+		return errors.New("Invalid artifact: missing IRIS header")
+	}
+	r := &artifactReader{data: data[len(artifactMagic):]}
+	if version := r.uint(); r.err == nil && version != ArtifactVersion {
+		return fmt.Errorf("Unsupported artifact version %d, expected %d", version, ArtifactVersion)
+	}
+	m := Metadata{
+		Contract:  r.string(),
+		Fields:    r.strings(),
+		Functions: r.strings(),
+	}
+	main := r.chunk()
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) > 0 {
+		return errors.New("Invalid artifact: unexpected data after the code")
+	}
+	b.main = main
+	b.metadata = m
+	return nil
+}
+
+type artifactWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *artifactWriter) uint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (w *artifactWriter) int(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf.Write(b[:binary.PutVarint(b[:], v)])
+}
+
+func (w *artifactWriter) string(s string) {
+	w.uint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *artifactWriter) strings(ss []string) {
+	w.uint(uint64(len(ss)))
+	for _, s := range ss {
+		w.string(s)
+	}
+}
+
+func (w *artifactWriter) chunk(c *chunk) error {
+	w.uint(uint64(len(c.constants)))
+	for _, v := range c.constants {
+		if err := w.constant(v); err != nil {
+			return err
+		}
+	}
+	w.uint(uint64(len(c.tokens)))
+	for _, tok := range c.tokens {
+		w.string(string(tok.Type))
+		w.string(tok.Lexeme)
+		w.uint(uint64(tok.Line))
+		w.uint(uint64(tok.Column))
+		w.uint(uint64(tok.Start))
+		w.uint(uint64(tok.End))
+	}
+	w.uint(uint64(len(c.code)))
+	for _, in := range c.code {
+		w.buf.WriteByte(byte(in.op))
+		w.int(int64(in.arg))
+		w.uint(uint64(in.tok))
+	}
+	return nil
+}
+
+func (w *artifactWriter) constant(v interface{}) error {
+	switch c := v.(type) {
+	case Int:
+		w.buf.WriteByte(constInteger)
+		w.int(int64(c))
+	case Float:
+		w.buf.WriteByte(constFloat)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], math.Float64bits(float64(c)))
+		w.buf.Write(b[:])
+	case Decimal:
+		w.buf.WriteByte(constDecimal)
+		w.string(c.String())
+	case String:
+		w.buf.WriteByte(constString)
+		w.string(string(c))
+	case Bool:
+		w.buf.WriteByte(constBoolean)
+		if c {
+			w.buf.WriteByte(1)
+		} else {
+			w.buf.WriteByte(0)
+		}
+	case []string:
+		w.buf.WriteByte(constKeys)
+		w.strings(c)
+	case *compiledFunction:
+		w.buf.WriteByte(constFunction)
+		return w.function(c)
+	case *compiledContract:
+		w.buf.WriteByte(constContract)
+		w.string(c.name)
+		w.uint(uint64(len(c.functions)))
+		for _, f := range c.functions {
+			if err := w.function(f); err != nil {
+				return err
+			}
+		}
+		w.strings(c.fields)
+		for _, init := range c.values {
+			if err := w.chunk(init); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("Cannot encode constant of type %T", v)
+	}
+	return nil
+}
+
+func (w *artifactWriter) function(f *compiledFunction) error {
+	w.string(f.name)
+	w.strings(f.params)
+	return w.chunk(f.body)
+}
+
+//artifactReader decodes an artifact, the first error stops the decoding
+type artifactReader struct {
+	data []byte
+	err  error
+}
+
+func (r *artifactReader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *artifactReader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.data) == 0 {
+		r.fail(errTruncatedArtifact)
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b
+}
+
+func (r *artifactReader) uint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.fail(errTruncatedArtifact)
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *artifactReader) int() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.fail(errTruncatedArtifact)
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+//count reads a number of items, each of them taking at least one byte
+func (r *artifactReader) count() int {
+	n := r.uint()
+	if n > uint64(len(r.data)) {
+		r.fail(errTruncatedArtifact)
+		return 0
+	}
+	return int(n)
+}
+
+func (r *artifactReader) string() string {
+	n := r.count()
+	if r.err != nil {
+		return ""
+	}
+	s := string(r.data[:n])
+	r.data = r.data[n:]
+	return s
+}
+
+func (r *artifactReader) strings() []string {
+	n := r.count()
+	var ss []string
+	for i := 0; i < n && r.err == nil; i++ {
+		ss = append(ss, r.string())
+	}
+	return ss
+}
+
+func (r *artifactReader) chunk() *chunk {
+	c := &chunk{}
+	n := r.count()
+	for i := 0; i < n && r.err == nil; i++ {
+		c.constants = append(c.constants, r.constant())
+	}
+	n = r.count()
+	for i := 0; i < n && r.err == nil; i++ {
+		c.tokens = append(c.tokens, token{
+			Type:   TokenType(r.string()),
+			Lexeme: r.string(),
+			Line:   int(r.uint()),
+			Column: int(r.uint()),
+			Start:  int(r.uint()),
+			End:    int(r.uint()),
+		})
+	}
+	n = r.count()
+	for i := 0; i < n && r.err == nil; i++ {
+		c.code = append(c.code, instruction{
+			op:  opcode(r.byte()),
+			arg: int(r.int()),
+			tok: int(r.uint()),
+		})
+	}
+	if r.err == nil {
+		r.fail(c.validate())
+	}
+	return c
+}
+
+func (r *artifactReader) constant() interface{} {
+	switch tag := r.byte(); tag {
+	case constInteger:
+		return Int(r.int())
+	case constFloat:
+		if len(r.data) < 8 {
+			r.fail(errTruncatedArtifact)
+			return nil
+		}
+		f := math.Float64frombits(binary.BigEndian.Uint64(r.data))
+		r.data = r.data[8:]
+		return Float(f)
+	case constDecimal:
+		d, err := ParseDecimal(r.string())
+		if err != nil && r.err == nil {
+			r.fail(fmt.Errorf("Invalid artifact: %s", err))
+		}
+		return d
+	case constString:
+		return String(r.string())
+	case constBoolean:
+		return Bool(r.byte() == 1)
+	case constKeys:
+		keys := r.strings()
+		if keys == nil {
+			keys = []string{}
+		}
+		return keys
+	case constFunction:
+		return r.function()
+	case constContract:
+		c := &compiledContract{
+			name: r.string(),
+		}
+		n := r.count()
+		for i := 0; i < n && r.err == nil; i++ {
+			c.functions = append(c.functions, r.function())
+		}
+		c.fields = r.strings()
+		for range c.fields {
+			c.values = append(c.values, r.chunk())
+		}
+		return c
+	default:
+		r.fail(fmt.Errorf("Invalid artifact: unknown constant tag %d", tag))
+		return nil
+	}
+}
+
+func (r *artifactReader) function() *compiledFunction {
+	return &compiledFunction{
+		name:   r.string(),
+		params: r.strings(),
+		body:   r.chunk(),
+	}
+}
+
+//validate ensures the instructions only refer to existing constants, tokens and instructions
+//so that a corrupted artifact is rejected before being run.
+//The stack and the types of the operands are checked by the virtual machine while running.
+func (c *chunk) validate() error {
+	for i, in := range c.code {
+		if int(in.op) >= len(vmGasCosts) {
+			return fmt.Errorf("Invalid artifact: unknown opcode %d", in.op)
+		}
+		if in.tok < 0 || (in.tok >= len(c.tokens) && (in.tok > 0 || opUsesToken(in.op))) {
+			return fmt.Errorf("Invalid artifact: instruction %d refers to an unknown token", i)
+		}
+		if !c.validArgument(in) {
+			return fmt.Errorf("Invalid artifact: instruction %d has an invalid operand %d", i, in.arg)
+		}
+	}
+	return nil
+}
+
+func opUsesToken(op opcode) bool {
+	switch op {
+	case opGetVar, opBinary, opNegate, opCallee, opCall, opIndex, opIndexSet, opGet, opSetTarget, opSet, opTransaction, opIterInit:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *chunk) validArgument(in instruction) bool {
+	var constant interface{}
+	if in.arg >= 0 && in.arg < len(c.constants) {
+		constant = c.constants[in.arg]
+	}
+	switch in.op {
+	case opConstant:
+		_, ok := constant.(Value)
+		return ok
+	case opGetVar, opSetVar, opDefine, opGet, opSetTarget, opSet:
+		_, ok := constant.(String)
+		return ok
+	case opObject:
+		_, ok := constant.([]string)
+		return ok
+	case opFunction:
+		_, ok := constant.(*compiledFunction)
+		return ok
+	case opContract:
+		_, ok := constant.(*compiledContract)
+		return ok
+	case opJump, opJumpIfFalse, opJumpIfFalseOrPop, opJumpIfTrueOrPop, opLoop, opIterNext:
+		return in.arg >= 0 && in.arg <= len(c.code)
+	case opReturn:
+		return in.arg >= -1 && in.arg <= len(c.code)
+	case opCall, opList:
+		return in.arg >= 0
+	default:
+		return true
+	}
+}
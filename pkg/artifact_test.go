@@ -0,0 +1,178 @@
+package uniris
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtifactRoundTrip(t *testing.T) {
+	b, err := Compile(apostilleContract)
+	assert.Nil(t, err)
+	data, err := b.MarshalBinary()
+	assert.Nil(t, err)
+
+	loaded, err := LoadBytecode(data)
+	assert.Nil(t, err)
+	again, err := loaded.MarshalBinary()
+	assert.Nil(t, err)
+	assert.Equal(t, data, again)
+
+	res, err := loaded.Run(nil)
+	assert.Nil(t, err)
+	_, err = res.Contract.Call("setApostille", String("123"))
+	assert.Nil(t, err)
+	id, err := res.Contract.Call("getRefugeeID")
+	assert.Nil(t, err)
+	assert.Equal(t, String("123"), id)
+}
+
+func TestArtifactConstants(t *testing.T) {
+	b, err := Compile("[1, 2.5, 1.25d, \"a\", true, {k: 1}, {}]")
+	assert.Nil(t, err)
+	data, err := b.MarshalBinary()
+	assert.Nil(t, err)
+
+	loaded, err := LoadBytecode(data)
+	assert.Nil(t, err)
+	res, err := loaded.Run(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "[1, 2.5, 1.25, \"a\", true, {\"k\": 1}, {}]\n", res.Output)
+}
+
+func TestArtifactMetadata(t *testing.T) {
+	b, err := Compile(apostilleContract)
+	assert.Nil(t, err)
+	data, err := b.MarshalBinary()
+	assert.Nil(t, err)
+	loaded, err := LoadBytecode(data)
+	assert.Nil(t, err)
+
+	assert.Equal(t, Metadata{
+		Contract:  "Apostille",
+		Fields:    []string{"isApostilled", "refugeeID"},
+		Functions: []string{"setApostille", "getRefugeeID"},
+	}, loaded.Metadata())
+}
+
+func TestArtifactAddress(t *testing.T) {
+	b1, err := Compile(apostilleContract)
+	assert.Nil(t, err)
+	b2, err := Compile(apostilleContract)
+	assert.Nil(t, err)
+	b3, err := Compile("a = 1")
+	assert.Nil(t, err)
+
+	addr1, err := b1.Address()
+	assert.Nil(t, err)
+	addr2, err := b2.Address()
+	assert.Nil(t, err)
+	addr3, err := b3.Address()
+	assert.Nil(t, err)
+	assert.Len(t, addr1, 64)
+	assert.Equal(t, addr1, addr2)
+	assert.NotEqual(t, addr1, addr3)
+}
+
+func TestArtifactKeepsErrorPositions(t *testing.T) {
+	b, err := Compile("a = 1\na - \"x\"")
+	assert.Nil(t, err)
+	data, err := b.MarshalBinary()
+	assert.Nil(t, err)
+	loaded, err := LoadBytecode(data)
+	assert.Nil(t, err)
+
+	_, err = loaded.Run(nil)
+	assert.EqualError(t, err, "Runtime error at - of line 2, column 3 - Cannot subtract string from number")
+}
+
+func TestLoadInvalidArtifact(t *testing.T) {
+	b, err := Compile("a = 1\na + 1")
+	assert.Nil(t, err)
+	data, err := b.MarshalBinary()
+	assert.Nil(t, err)
+
+	_, err = LoadBytecode([]byte("ELF"))
+	assert.EqualError(t, err, "Invalid artifact: missing IRIS header")
+
+	_, err = LoadBytecode(append([]byte("IRIS"), 2))
+	assert.EqualError(t, err, "Unsupported artifact version 2, expected 1")
+
+	_, err = LoadBytecode(data[:len(data)-2])
+	assert.EqualError(t, err, "Invalid artifact: unexpected end of data")
+
+	_, err = LoadBytecode(append(append([]byte{}, data...), 0))
+	assert.EqualError(t, err, "Invalid artifact: unexpected data after the code")
+
+	//The last instruction is the output: opcode, operand and token
+	corrupted := append([]byte{}, data...)
+	corrupted[len(corrupted)-3] = 0xff
+	_, err = LoadBytecode(corrupted)
+	assert.EqualError(t, err, "Invalid artifact: unknown opcode 255")
+
+	corrupted = append([]byte{}, data...)
+	corrupted[len(corrupted)-3] = byte(opJump)
+	corrupted[len(corrupted)-2] = 0x7e
+	_, err = LoadBytecode(corrupted)
+	assert.EqualError(t, err, "Invalid artifact: instruction 6 has an invalid operand 63")
+}
+
+func TestRunCorruptedArtifact(t *testing.T) {
+	b, err := Compile(`
+contract Counter {
+    count = 0
+    function add(n) {
+        count = count + n
+        return count
+    }
+}
+o = {a: [1, 2]}
+o.b = o.a[0] and !false or -1
+o.a[1] = "x"
+for k in o {
+    print k
+}
+i = 0
+while i < 2 {
+    i = i + 1
+}
+Counter.add(len(o.a))
+`)
+	assert.Nil(t, err)
+	data, err := b.MarshalBinary()
+	assert.Nil(t, err)
+
+	run := func(corrupted []byte) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		loaded, err := LoadBytecode(corrupted)
+		if err != nil {
+			return nil
+		}
+		env := NewEnvironment(nil)
+		env.SetGasLimit(1000)
+		env.SetOutput(ioutil.Discard)
+		loaded.Run(env)
+		return nil
+	}
+
+	//The small values cover the opcodes, the operands and the counts, the large ones the varint continuations
+	values := []byte{0x7f, 0x80, 0xff}
+	for v := byte(0); v < 40; v++ {
+		values = append(values, v)
+	}
+	for i := len(artifactMagic) + 1; i < len(data); i++ {
+		for _, v := range values {
+			corrupted := append([]byte{}, data...)
+			corrupted[i] = v
+			if err := run(corrupted); err != nil {
+				t.Fatalf("Byte %d set to %d: %s", i, v, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,247 @@
+//Package ast declares the syntax tree of the smart contract language.
+//Trees are produced by uniris.Parse and can be explored with Walk or Inspect.
+package ast
+
+//Span locates a node in the source.
+//Line and Column are the position of its first character, Start and End its byte offsets (End excluded).
+type Span struct {
+	Line   int
+	Column int
+	Start  int
+	End    int
+}
+
+//Position returns the location of the node
+func (s Span) Position() Span {
+	return s
+}
+
+//Node is implemented by all the nodes of the tree
+type Node interface {
+	Position() Span
+}
+
+//Statement is a node executed for its effect
+type Statement interface {
+	Node
+	stmtNode()
+}
+
+//Expression is a node producing a value
+type Expression interface {
+	Node
+	exprNode()
+}
+
+//Program is the root of the tree
+type Program struct {
+	Span
+	Statements []Statement
+}
+
+//Ident is an identifier. As an expression it reads a variable.
+type Ident struct {
+	Span
+	Name string
+}
+
+//STATEMENTS
+
+//ExpressionStmt evaluates an expression, its value is part of the output at top level
+type ExpressionStmt struct {
+	Span
+	X Expression
+}
+
+//PrintStmt prints the value of an expression
+type PrintStmt struct {
+	Span
+	X Expression
+}
+
+//BlockStmt is a list of statements between braces
+type BlockStmt struct {
+	Span
+	Statements []Statement
+}
+
+//IfStmt executes Then when Cond is truthy, Else otherwise. Else can be nil.
+type IfStmt struct {
+	Span
+	Cond Expression
+	Then Statement
+	Else Statement
+}
+
+//WhileStmt executes Body as long as Cond is truthy
+type WhileStmt struct {
+	Span
+	Cond Expression
+	Body Statement
+}
+
+//ForStmt executes Init, then Body followed by Post as long as Cond is truthy.
+//Init, Cond and Post are nil when omitted, a missing Cond is always true.
+type ForStmt struct {
+	Span
+	Init Statement
+	Cond Expression
+	Post Expression
+	Body Statement
+}
+
+//ForInStmt executes Body for each element of a list or each key of an object
+type ForInStmt struct {
+	Span
+	Name       *Ident
+	Collection Expression
+	Body       Statement
+}
+
+//FuncDecl declares a function
+type FuncDecl struct {
+	Span
+	Name   *Ident
+	Params []*Ident
+	Body   *BlockStmt
+}
+
+//ContractDecl declares a contract with its state variables and its functions
+type ContractDecl struct {
+	Span
+	Name      *Ident
+	State     []*AssignExpr
+	Functions []*FuncDecl
+}
+
+//ReturnStmt returns a value from a function
+type ReturnStmt struct {
+	Span
+	Value Expression
+}
+
+//EXPRESSIONS
+
+//AssignExpr assigns a value to a variable
+type AssignExpr struct {
+	Span
+	Name  *Ident
+	Value Expression
+}
+
+//BinaryExpr is an arithmetic, comparison or equality operation
+type BinaryExpr struct {
+	Span
+	Left     Expression
+	Operator string
+	Right    Expression
+}
+
+//LogicalExpr is an and/or operation
+type LogicalExpr struct {
+	Span
+	Left     Expression
+	Operator string
+	Right    Expression
+}
+
+//UnaryExpr is a negation (- or !)
+type UnaryExpr struct {
+	Span
+	Operator string
+	X        Expression
+}
+
+//GroupExpr is an expression between parentheses
+type GroupExpr struct {
+	Span
+	X Expression
+}
+
+//Literal is a number, a string, a boolean or nil
+type Literal struct {
+	Span
+	Value interface{}
+}
+
+//CallExpr calls a function
+type CallExpr struct {
+	Span
+	Callee Expression
+	Args   []Expression
+}
+
+//ListExpr is a list literal
+type ListExpr struct {
+	Span
+	Elements []Expression
+}
+
+//ObjectExpr is an object literal, Keys and Values are in declaration order
+type ObjectExpr struct {
+	Span
+	Keys   []string
+	Values []Expression
+}
+
+//IndexExpr reads an element: X[Index]
+type IndexExpr struct {
+	Span
+	X     Expression
+	Index Expression
+}
+
+//IndexAssignExpr assigns an element: X[Index] = Value
+type IndexAssignExpr struct {
+	Span
+	X     Expression
+	Index Expression
+	Value Expression
+}
+
+//GetExpr reads a property: X.Name
+type GetExpr struct {
+	Span
+	X    Expression
+	Name *Ident
+}
+
+//SetExpr assigns a property: X.Name = Value
+type SetExpr struct {
+	Span
+	X     Expression
+	Name  *Ident
+	Value Expression
+}
+
+//TransactionExpr is the transaction triggering a contract execution
+type TransactionExpr struct {
+	Span
+}
+
+func (*ExpressionStmt) stmtNode() {}
+func (*PrintStmt) stmtNode()      {}
+func (*BlockStmt) stmtNode()      {}
+func (*IfStmt) stmtNode()         {}
+func (*WhileStmt) stmtNode()      {}
+func (*ForStmt) stmtNode()        {}
+func (*ForInStmt) stmtNode()      {}
+func (*FuncDecl) stmtNode()       {}
+func (*ContractDecl) stmtNode()   {}
+func (*ReturnStmt) stmtNode()     {}
+
+func (*Ident) exprNode()           {}
+func (*AssignExpr) exprNode()      {}
+func (*BinaryExpr) exprNode()      {}
+func (*LogicalExpr) exprNode()     {}
+func (*UnaryExpr) exprNode()       {}
+func (*GroupExpr) exprNode()       {}
+func (*Literal) exprNode()         {}
+func (*CallExpr) exprNode()        {}
+func (*ListExpr) exprNode()        {}
+func (*ObjectExpr) exprNode()      {}
+func (*IndexExpr) exprNode()       {}
+func (*IndexAssignExpr) exprNode() {}
+func (*GetExpr) exprNode()         {}
+func (*SetExpr) exprNode()         {}
+func (*TransactionExpr) exprNode() {}
@@ -0,0 +1,134 @@
+package ast
+
+import "fmt"
+
+//Visitor is called for each node encountered by Walk.
+//If the returned visitor w is not nil, Walk visits each of the children of node with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+//Walk traverses a tree in depth-first order
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, s := range n.Statements {
+			Walk(v, s)
+		}
+	case *Ident, *Literal, *TransactionExpr:
+		//No children
+
+	case *ExpressionStmt:
+		Walk(v, n.X)
+	case *PrintStmt:
+		Walk(v, n.X)
+	case *BlockStmt:
+		for _, s := range n.Statements {
+			Walk(v, s)
+		}
+	case *IfStmt:
+		Walk(v, n.Cond)
+		Walk(v, n.Then)
+		if n.Else != nil {
+			Walk(v, n.Else)
+		}
+	case *WhileStmt:
+		Walk(v, n.Cond)
+		Walk(v, n.Body)
+	case *ForStmt:
+		if n.Init != nil {
+			Walk(v, n.Init)
+		}
+		if n.Cond != nil {
+			Walk(v, n.Cond)
+		}
+		if n.Post != nil {
+			Walk(v, n.Post)
+		}
+		Walk(v, n.Body)
+	case *ForInStmt:
+		Walk(v, n.Name)
+		Walk(v, n.Collection)
+		Walk(v, n.Body)
+	case *FuncDecl:
+		Walk(v, n.Name)
+		for _, p := range n.Params {
+			Walk(v, p)
+		}
+		Walk(v, n.Body)
+	case *ContractDecl:
+		Walk(v, n.Name)
+		for _, s := range n.State {
+			Walk(v, s)
+		}
+		for _, f := range n.Functions {
+			Walk(v, f)
+		}
+	case *ReturnStmt:
+		Walk(v, n.Value)
+
+	case *AssignExpr:
+		Walk(v, n.Name)
+		Walk(v, n.Value)
+	case *BinaryExpr:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case *LogicalExpr:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case *UnaryExpr:
+		Walk(v, n.X)
+	case *GroupExpr:
+		Walk(v, n.X)
+	case *CallExpr:
+		Walk(v, n.Callee)
+		for _, a := range n.Args {
+			Walk(v, a)
+		}
+	case *ListExpr:
+		for _, e := range n.Elements {
+			Walk(v, e)
+		}
+	case *ObjectExpr:
+		for _, e := range n.Values {
+			Walk(v, e)
+		}
+	case *IndexExpr:
+		Walk(v, n.X)
+		Walk(v, n.Index)
+	case *IndexAssignExpr:
+		Walk(v, n.X)
+		Walk(v, n.Index)
+		Walk(v, n.Value)
+	case *GetExpr:
+		Walk(v, n.X)
+		Walk(v, n.Name)
+	case *SetExpr:
+		Walk(v, n.X)
+		Walk(v, n.Name)
+		Walk(v, n.Value)
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+//Inspect traverses a tree in depth-first order, calling f for each node and then f(nil) after its children.
+//The children of a node are skipped when f returns false.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,117 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspect(t *testing.T) {
+	prog := &Program{
+		Statements: []Statement{
+			&ExpressionStmt{
+				X: &AssignExpr{
+					Name: &Ident{Name: "a"},
+					Value: &BinaryExpr{
+						Left:     &Literal{Value: 1},
+						Operator: "+",
+						Right:    &Ident{Name: "b"},
+					},
+				},
+			},
+		},
+	}
+
+	names := make([]string, 0)
+	Inspect(prog, func(n Node) bool {
+		if id, ok := n.(*Ident); ok {
+			names = append(names, id.Name)
+		}
+		return true
+	})
+	assert.Equal(t, []string{"a", "b"}, names)
+}
+
+func TestInspectSkipsChildren(t *testing.T) {
+	prog := &Program{
+		Statements: []Statement{
+			&FuncDecl{
+				Name: &Ident{Name: "f"},
+				Body: &BlockStmt{
+					Statements: []Statement{
+						&ReturnStmt{Value: &Ident{Name: "x"}},
+					},
+				},
+			},
+			&ExpressionStmt{X: &Ident{Name: "y"}},
+		},
+	}
+
+	names := make([]string, 0)
+	Inspect(prog, func(n Node) bool {
+		if id, ok := n.(*Ident); ok {
+			names = append(names, id.Name)
+		}
+		_, isFunc := n.(*FuncDecl)
+		return !isFunc
+	})
+	assert.Equal(t, []string{"y"}, names)
+}
+
+type depthVisitor struct {
+	depth *int
+	max   *int
+}
+
+func (v depthVisitor) Visit(n Node) Visitor {
+	if n == nil {
+		*v.depth--
+		return nil
+	}
+	*v.depth++
+	if *v.depth > *v.max {
+		*v.max = *v.depth
+	}
+	return v
+}
+
+func TestWalk(t *testing.T) {
+	prog := &Program{
+		Statements: []Statement{
+			&IfStmt{
+				Cond: &Literal{Value: true},
+				Then: &PrintStmt{X: &UnaryExpr{Operator: "-", X: &Literal{Value: 1}}},
+			},
+		},
+	}
+
+	depth, max := 0, 0
+	Walk(depthVisitor{depth: &depth, max: &max}, prog)
+	assert.Equal(t, 0, depth)
+	assert.Equal(t, 5, max)
+}
+
+func TestWalkFor(t *testing.T) {
+	loop := &ForStmt{
+		Init: &ExpressionStmt{X: &AssignExpr{Name: &Ident{Name: "i"}, Value: &Literal{Value: 0}}},
+		Post: &AssignExpr{Name: &Ident{Name: "j"}, Value: &Literal{Value: 1}},
+		Body: &BlockStmt{Statements: []Statement{&PrintStmt{X: &Ident{Name: "k"}}}},
+	}
+
+	nodes := make([]Node, 0)
+	Inspect(loop, func(n Node) bool {
+		if n != nil {
+			nodes = append(nodes, n)
+		}
+		return true
+	})
+	assert.Equal(t, 11, len(nodes))
+	assert.Equal(t, loop.Init, nodes[1])
+	assert.Equal(t, loop.Post, nodes[5])
+	assert.Equal(t, loop.Body, nodes[8])
+
+	depth, max := 0, 0
+	Walk(depthVisitor{depth: &depth, max: &max}, loop)
+	assert.Equal(t, 0, depth)
+	assert.Equal(t, 4, max)
+}
@@ -0,0 +1,352 @@
+package uniris
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+var (
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	decimalType = reflect.TypeOf(Decimal{})
+	valueType   = reflect.TypeOf((*Value)(nil)).Elem()
+)
+
+//Bind exposes a Go function to the scripts as a native function.
+//The arguments are converted to the Go parameter types and the result back to a script value.
+//The function returns nothing, a value, an error or a value and an error.
+func Bind(name string, fn interface{}) (NativeFunc, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("Cannot bind %s: %T is not a function", name, fn)
+	}
+	t := v.Type()
+
+	switch {
+	case t.NumOut() > 2:
+		return nil, fmt.Errorf("Cannot bind %s: functions return at most a value and an error", name)
+	case t.NumOut() == 2 && t.Out(1) != errorType:
+		return nil, fmt.Errorf("Cannot bind %s: the second result must be an error", name)
+	}
+
+	sig := Signature{
+		Params:   make([]ParamType, t.NumIn()),
+		Variadic: t.IsVariadic(),
+	}
+	for i := 0; i < t.NumIn(); i++ {
+		in := t.In(i)
+		if sig.Variadic && i == t.NumIn()-1 {
+			in = in.Elem()
+		}
+		param, err := paramType(in)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot bind %s: %s", name, err)
+		}
+		sig.Params[i] = param
+	}
+
+	return boundGoFunc{
+		name: name,
+		sig:  sig,
+		fn:   v,
+	}, nil
+}
+
+//MustBind is like Bind but panics if the function cannot be bound
+func MustBind(name string, fn interface{}) NativeFunc {
+	f, err := Bind(name, fn)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+//BindMethods exposes the exported methods of a Go value as native functions named after them,
+//to be registered as a module with WithModule
+func BindMethods(receiver interface{}) ([]NativeFunc, error) {
+	v := reflect.ValueOf(receiver)
+	funcs := make([]NativeFunc, 0, v.NumMethod())
+	for i := 0; i < v.NumMethod(); i++ {
+		f, err := Bind(v.Type().Method(i).Name, v.Method(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		funcs = append(funcs, f)
+	}
+	return funcs, nil
+}
+
+//paramType gives the script type accepted by a Go parameter
+func paramType(t reflect.Type) (ParamType, error) {
+	if t == decimalType {
+		//Decimal parameters accept numbers too
+		return TypeAny, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return TypeString, nil
+	case reflect.Bool:
+		return TypeBoolean, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return TypeNumber, nil
+	case reflect.Slice:
+		return TypeList, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return "", fmt.Errorf("unsupported parameter type %s, map keys must be strings", t)
+		}
+		return TypeObject, nil
+	case reflect.Interface:
+		return TypeAny, nil
+	default:
+		return "", fmt.Errorf("unsupported parameter type %s", t)
+	}
+}
+
+//boundGoFunc is a Go function called through reflection
+type boundGoFunc struct {
+	name string
+	sig  Signature
+	fn   reflect.Value
+}
+
+func (f boundGoFunc) Name() string {
+	return f.name
+}
+
+func (f boundGoFunc) Signature() Signature {
+	return f.sig
+}
+
+func (f boundGoFunc) Call(args ...Value) (res Value, err error) {
+	//Call may be used directly, the arity is checked before looking up the parameter types
+	if err := f.sig.check(f.name, args); err != nil {
+		return nil, err
+	}
+	t := f.fn.Type()
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var pt reflect.Type
+		if t.IsVariadic() && i >= t.NumIn()-1 {
+			pt = t.In(t.NumIn() - 1).Elem()
+		} else {
+			pt = t.In(i)
+		}
+		v, err := toGo(arg, pt)
+		if err != nil {
+			return nil, fmt.Errorf("%s argument %d: %s", f.name, i+1, err)
+		}
+		in[i] = v
+	}
+
+	defer func() {
+		if x := recover(); x != nil {
+			res = nil
+			err = fmt.Errorf("%s failed: %v", f.name, x)
+		}
+	}()
+	out := f.fn.Call(in)
+
+	if len(out) > 0 && t.Out(len(out)-1) == errorType {
+		if e := out[len(out)-1]; !e.IsNil() {
+			return nil, e.Interface().(error)
+		}
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	val, err := fromGo(out[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s result: %s", f.name, err)
+	}
+	return val, nil
+}
+
+//toGo converts a script value into a Go value of a given type
+func toGo(v Value, t reflect.Type) (reflect.Value, error) {
+	if v == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map:
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("Cannot convert nil to %s", t)
+	}
+	if t == valueType {
+		return reflect.ValueOf(&v).Elem(), nil
+	}
+	if t == decimalType {
+		if _, ok := v.(Float); ok {
+			return reflect.Value{}, fmt.Errorf("Cannot convert number %v to decimal, convert it with decimal()", v)
+		}
+		d, err := toDecimal(v)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("Cannot convert %s to decimal", typeName(v))
+		}
+		return reflect.ValueOf(d), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		g := ToGo(v)
+		if g == nil || !reflect.TypeOf(g).Implements(t) {
+			return reflect.Value{}, fmt.Errorf("Cannot convert %s to %s", typeName(v), t)
+		}
+		return reflect.ValueOf(g).Convert(t), nil
+	case reflect.String:
+		if s, ok := v.(String); ok {
+			return reflect.ValueOf(s).Convert(t), nil
+		}
+	case reflect.Bool:
+		if b, ok := v.(Bool); ok {
+			return reflect.ValueOf(b).Convert(t), nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, ok := integral(v); ok {
+			r := reflect.New(t).Elem()
+			if r.OverflowInt(i) {
+				return reflect.Value{}, fmt.Errorf("Number %d overflows %s", i, t)
+			}
+			r.SetInt(i)
+			return r, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, ok := integral(v); ok {
+			r := reflect.New(t).Elem()
+			if i < 0 || r.OverflowUint(uint64(i)) {
+				return reflect.Value{}, fmt.Errorf("Number %d overflows %s", i, t)
+			}
+			r.SetUint(uint64(i))
+			return r, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if isNumber(v) {
+			return reflect.ValueOf(toFloat(v)).Convert(t), nil
+		}
+	case reflect.Slice:
+		if l, ok := v.(*list); ok {
+			r := reflect.MakeSlice(t, len(l.elements), len(l.elements))
+			for i, el := range l.elements {
+				e, err := toGo(el, t.Elem())
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("element %d: %s", i, err)
+				}
+				r.Index(i).Set(e)
+			}
+			return r, nil
+		}
+	case reflect.Map:
+		if o, ok := v.(*object); ok && t.Key().Kind() == reflect.String {
+			r := reflect.MakeMapWithSize(t, len(o.keys))
+			for _, k := range o.keys {
+				e, err := toGo(o.lookup(k), t.Elem())
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("key %s: %s", k, err)
+				}
+				r.SetMapIndex(reflect.ValueOf(k.String()).Convert(t.Key()), e)
+			}
+			return r, nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("Cannot convert %s to %s", typeName(v), t)
+}
+
+//integral returns the integer value of an integer or of a float without fractional part
+func integral(v Value) (int64, bool) {
+	switch n := v.(type) {
+	case Int:
+		return int64(n), true
+	case Float:
+		if f := float64(n); f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
+
+//fromGo converts a Go value into a script value
+func fromGo(v reflect.Value) (Value, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.CanInterface() {
+		if val, ok := v.Interface().(Value); ok {
+			return val, nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return fromGo(v.Elem())
+	case reflect.String:
+		return String(v.String()), nil
+	case reflect.Bool:
+		return Bool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("Number %d overflows the integers", v.Uint())
+		}
+		return Int(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return Float(v.Float()), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		elements := make([]Value, v.Len())
+		for i := range elements {
+			el, err := fromGo(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = el
+		}
+		return newList(elements...), nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("Cannot convert %s, map keys must be strings", v.Type())
+		}
+		if v.IsNil() {
+			return nil, nil
+		}
+		//Keys are sorted so the same map always gives the same object
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		o := newObject()
+		for _, k := range keys {
+			el, err := fromGo(v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())))
+			if err != nil {
+				return nil, err
+			}
+			o.set(String(k), el)
+		}
+		return o, nil
+	case reflect.Struct:
+		o := newObject()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			el, err := fromGo(v.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			o.set(String(field.Name), el)
+		}
+		return o, nil
+	default:
+		return nil, fmt.Errorf("Cannot convert %s", v.Type())
+	}
+}
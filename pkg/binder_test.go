@@ -0,0 +1,161 @@
+package uniris
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindFunction(t *testing.T) {
+	f, err := Bind("above", func(s string, threshold float64) (bool, error) {
+		if s == "" {
+			return false, errors.New("Empty label")
+		}
+		return float64(len(s)) > threshold, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, Signature{Params: []ParamType{TypeString, TypeNumber}}, f.Signature())
+
+	in := NewInterpreter(WithNatives(f))
+	res, err := in.Run("above(\"abc\", 2)\nabove(\"abc\", 2.5 * 2)")
+	assert.Nil(t, err)
+	assert.Equal(t, "true\nfalse\n", res.Output)
+
+	_, err = in.Run("above(\"\", 1)")
+	assert.EqualError(t, err, "Runtime error at above of line 1, column 1 - Empty label")
+
+	_, err = in.Run("above(1, 1)")
+	assert.EqualError(t, err, "Runtime error at above of line 1, column 1 - above expects a string as argument 1, got number")
+}
+
+func TestBindConversions(t *testing.T) {
+	in := NewInterpreter(WithNatives(
+		MustBind("sum", func(values []int) int {
+			total := 0
+			for _, v := range values {
+				total += v
+			}
+			return total
+		}),
+		MustBind("byte", func(b uint8) uint8 { return b }),
+		MustBind("labels", func(m map[string]int) []string {
+			labels := make([]string, 0, len(m))
+			for k, v := range m {
+				labels = append(labels, strings.Repeat(k, v))
+			}
+			return labels
+		}),
+		MustBind("counts", func() map[string]int { return map[string]int{"b": 2, "a": 1} }),
+		MustBind("fee", func(amount Decimal) (Decimal, error) { return amount.Mul(MustParseDecimal("0.01")) }),
+		MustBind("join", func(sep string, parts ...string) string { return strings.Join(parts, sep) }),
+		MustBind("describe", typeOf),
+		MustBind("nothing", func() {}),
+	))
+
+	res, err := in.Run(`
+sum([1, 2, 3.0])
+byte(255)
+labels({a: 2})
+counts()
+fee(150)
+fee(1.5d)
+join("-", "a", "b")
+join("-")
+describe([1])
+describe({a: 1})
+nothing()
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, "6\n255\n[\"aa\"]\n{\"a\": 1, \"b\": 2}\n1.5\n0.015\na-b\n\nlist\nobject\n", res.Output)
+
+	_, err = in.Run("byte(256)")
+	assert.EqualError(t, err, "Runtime error at byte of line 1, column 1 - byte argument 1: Number 256 overflows uint8")
+	_, err = in.Run("byte(-1)")
+	assert.EqualError(t, err, "Runtime error at byte of line 1, column 1 - byte argument 1: Number -1 overflows uint8")
+	_, err = in.Run("sum([1, 1.5])")
+	assert.EqualError(t, err, "Runtime error at sum of line 1, column 1 - sum argument 1: element 1: Cannot convert number to int")
+	_, err = in.Run("fee(1.5)")
+	assert.EqualError(t, err, "Runtime error at fee of line 1, column 1 - fee argument 1: Cannot convert number 1.5 to decimal, convert it with decimal()")
+	_, err = in.Run("fee(\"1\")")
+	assert.EqualError(t, err, "Runtime error at fee of line 1, column 1 - fee argument 1: Cannot convert string to decimal")
+}
+
+//typeOf describes the Go value a script value is converted to
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "other"
+	}
+}
+
+type accounts struct {
+	balances map[string]int64
+}
+
+func (a *accounts) Balance(account string) (int64, error) {
+	balance, exist := a.balances[account]
+	if !exist {
+		return 0, errors.New("Unknown account " + account)
+	}
+	return balance, nil
+}
+
+func (a *accounts) Holders() []string {
+	return []string{"alice", "bob"}
+}
+
+func TestBindMethods(t *testing.T) {
+	funcs, err := BindMethods(&accounts{balances: map[string]int64{"alice": 10}})
+	assert.Nil(t, err)
+	assert.Len(t, funcs, 2)
+
+	in := NewInterpreter(WithModule("chain", funcs...))
+	res, err := in.Run("chain.Balance(\"alice\")\nchain.Holders()")
+	assert.Nil(t, err)
+	assert.Equal(t, "10\n[\"alice\", \"bob\"]\n", res.Output)
+
+	_, err = in.Run("chain.Balance(\"carol\")")
+	assert.EqualError(t, err, "Runtime error at ) of line 1, column 22 - Unknown account carol")
+}
+
+func TestBindErrors(t *testing.T) {
+	_, err := Bind("f", 1)
+	assert.EqualError(t, err, "Cannot bind f: int is not a function")
+
+	_, err = Bind("f", func() (int, int, error) { return 0, 0, nil })
+	assert.EqualError(t, err, "Cannot bind f: functions return at most a value and an error")
+
+	_, err = Bind("f", func() (int, int) { return 0, 0 })
+	assert.EqualError(t, err, "Cannot bind f: the second result must be an error")
+
+	_, err = Bind("f", func(c chan int) {})
+	assert.EqualError(t, err, "Cannot bind f: unsupported parameter type chan int")
+
+	_, err = Bind("f", func(m map[int]int) {})
+	assert.EqualError(t, err, "Cannot bind f: unsupported parameter type map[int]int, map keys must be strings")
+
+	f := MustBind("boom", func() { panic("unexpected") })
+	_, err = f.Call()
+	assert.EqualError(t, err, "boom failed: unexpected")
+
+	f = MustBind("pair", func(a, b int) int { return a + b })
+	_, err = f.Call(Int(1), Int(2), Int(3))
+	assert.EqualError(t, err, "pair expects 2 arguments, got 3")
+	_, err = f.Call()
+	assert.EqualError(t, err, "pair expects 2 arguments, got 0")
+
+	f = MustBind("join", func(sep string, parts ...string) string { return strings.Join(parts, sep) })
+	_, err = f.Call()
+	assert.EqualError(t, err, "join expects at least 1 arguments, got 0")
+	res, err := f.Call(String("-"), String("a"), String("b"))
+	assert.Nil(t, err)
+	assert.Equal(t, String("a-b"), res)
+
+	assert.Panics(t, func() { MustBind("f", nil) })
+}
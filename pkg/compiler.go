@@ -0,0 +1,423 @@
+package uniris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type opcode byte
+
+const (
+	opConstant opcode = iota
+	opNil
+	opPop
+	opOutput
+	opGetVar
+	opSetVar
+	opDefine
+	opBinary
+	opNot
+	opNegate
+	opJump
+	opJumpIfFalse
+	opJumpIfFalseOrPop
+	opJumpIfTrueOrPop
+	opLoop
+	opCallee
+	opCall
+	opList
+	opObject
+	opIndex
+	opIndexSet
+	opGet
+	opSetTarget
+	opSet
+	opTransaction
+	opPrint
+	opPushScope
+	opPopScope
+	opFunction
+	opContract
+	opReturn
+	opIterInit
+	opIterNext
+)
+
+//instruction is a single virtual machine operation.
+//arg is an operand count, a constant or a jump target depending on the opcode and
+//tok the index of the token used to position the runtime errors.
+type instruction struct {
+	op  opcode
+	arg int
+	tok int
+}
+
+//chunk is a sequence of instructions with the constants and tokens they refer to
+type chunk struct {
+	code      []instruction
+	constants []interface{}
+	tokens    []token
+}
+
+//compiledFunction is a function declaration compiled to bytecode
+type compiledFunction struct {
+	name   string
+	params []string
+	body   *chunk
+}
+
+func (f *compiledFunction) Kind() Kind {
+	return KindFunction
+}
+
+func (f *compiledFunction) String() string {
+	return fmt.Sprintf("<function %s>", f.name)
+}
+
+func (f *compiledFunction) isValue() {}
+
+func (f *compiledFunction) call(env *Environment, args ...Value) (Value, error) {
+	if err := env.checkContext(); err != nil {
+		return nil, err
+	}
+	if err := env.enterCall(); err != nil {
+		return nil, err
+	}
+	defer env.leaveCall()
+	scope := NewEnvironment(env)
+
+	if len(args) != len(f.params) {
+		return nil, errors.New("Missing function parameters")
+	}
+	for i, p := range f.params {
+		scope.define(p, args[i])
+	}
+	return run(f.body, scope, nil)
+}
+
+//compiledContract is a contract declaration compiled to bytecode
+type compiledContract struct {
+	name      string
+	functions []*compiledFunction
+	fields    []string
+	values    []*chunk
+}
+
+//Bytecode is a smart contract code compiled for the virtual machine.
+//It can be run several times without being parsed again.
+type Bytecode struct {
+	main     *chunk
+	metadata Metadata
+}
+
+//Metadata describes the contract declared by a compiled code
+type Metadata struct {
+	//Contract is the name of the declared contract, empty when the code does not declare any
+	Contract  string
+	Fields    []string
+	Functions []string
+}
+
+//Metadata returns the description of the contract declared by the code
+func (b *Bytecode) Metadata() Metadata {
+	return Metadata{
+		Contract:  b.metadata.Contract,
+		Fields:    append([]string{}, b.metadata.Fields...),
+		Functions: append([]string{}, b.metadata.Functions...),
+	}
+}
+
+//Compile parses a smart contract code and compiles it to bytecode
+func Compile(code string) (*Bytecode, error) {
+	stmts, err := parseCode(code)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bytecode{}
+	c := newCompiler()
+	for _, s := range stmts {
+		c.statement(s, true)
+		if contract, ok := s.(contractStatement); ok {
+			b.metadata = contractMetadata(contract)
+		}
+	}
+	b.main = c.chunk
+	return b, nil
+}
+
+func contractMetadata(stmt contractStatement) Metadata {
+	m := Metadata{
+		Contract: stmt.name.Lexeme,
+	}
+	for _, s := range stmt.state {
+		m.Fields = append(m.Fields, s.op.Lexeme)
+	}
+	for _, f := range stmt.functions {
+		m.Functions = append(m.Functions, f.name.Lexeme)
+	}
+	return m
+}
+
+//Run executes the bytecode with the same semantics as Interpret
+func (b *Bytecode) Run(env *Environment) (*Result, error) {
+	return b.RunContext(context.Background(), env)
+}
+
+//RunContext executes the bytecode until the context is canceled or times out
+func (b *Bytecode) RunContext(ctx context.Context, env *Environment) (*Result, error) {
+	return b.run(ctx, env, nil)
+}
+
+func (b *Bytecode) run(ctx context.Context, env *Environment, natives map[string]Value) (*Result, error) {
+	env, exec := startExecution(ctx, env, natives)
+
+	res := &Result{}
+	if _, err := run(b.main, env, res); err != nil {
+		return nil, err
+	}
+	if err := res.finish(exec); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type compiler struct {
+	chunk     *chunk
+	constants map[Value]int
+}
+
+func newCompiler() *compiler {
+	return &compiler{
+		chunk:     &chunk{},
+		constants: make(map[Value]int),
+	}
+}
+
+func (c *compiler) emit(op opcode, arg int) int {
+	c.chunk.code = append(c.chunk.code, instruction{op: op, arg: arg})
+	return len(c.chunk.code) - 1
+}
+
+//emitAt emits an instruction whose runtime errors are positioned on a token
+func (c *compiler) emitAt(op opcode, arg int, tok token) int {
+	c.chunk.tokens = append(c.chunk.tokens, tok)
+	c.chunk.code = append(c.chunk.code, instruction{op: op, arg: arg, tok: len(c.chunk.tokens) - 1})
+	return len(c.chunk.code) - 1
+}
+
+//patch makes a jump instruction target the next emitted instruction
+func (c *compiler) patch(jump int) {
+	c.chunk.code[jump].arg = len(c.chunk.code)
+}
+
+func (c *compiler) constant(v interface{}) int {
+	switch v.(type) {
+	case Int, Float, String, Bool:
+		k := v.(Value)
+		if i, exist := c.constants[k]; exist {
+			return i
+		}
+		c.constants[k] = len(c.chunk.constants)
+	}
+	c.chunk.constants = append(c.chunk.constants, v)
+	return len(c.chunk.constants) - 1
+}
+
+//name adds a variable or a property name to the constants
+func (c *compiler) name(s string) int {
+	return c.constant(String(s))
+}
+
+//statement compiles a statement, the values of the top level ones are part of the output
+func (c *compiler) statement(stmt statement, topLevel bool) {
+	switch s := stmt.(type) {
+	case expressionStmt:
+		c.expression(s.exp)
+		c.result(topLevel)
+	case printStmt:
+		c.expression(s.exp)
+		c.emit(opPrint, 0)
+	case blockStmt:
+		c.block(s)
+	case ifStatement:
+		c.expression(s.cond)
+		elseJump := c.emit(opJumpIfFalse, 0)
+		c.statement(s.thenStmt, false)
+		if s.elseStmt == nil {
+			c.patch(elseJump)
+			return
+		}
+		endJump := c.emit(opJump, 0)
+		c.patch(elseJump)
+		c.statement(s.elseStmt, false)
+		c.patch(endJump)
+	case whileStatement:
+		start := len(c.chunk.code)
+		c.expression(s.cond)
+		exitJump := c.emit(opJumpIfFalse, 0)
+		c.statement(s.body, false)
+		c.emit(opLoop, start)
+		c.patch(exitJump)
+	case forStatement:
+		c.statement(s.desugar(), false)
+	case forInStatement:
+		c.expression(s.collection)
+		c.emitAt(opIterInit, 0, s.name)
+		start := c.emit(opIterNext, 0)
+		c.emit(opPushScope, 0)
+		c.emit(opDefine, c.name(s.name.Lexeme))
+		c.statement(s.body, false)
+		c.emit(opPopScope, 0)
+		c.emit(opLoop, start)
+		c.patch(start)
+	case funcStatement:
+		c.emit(opFunction, c.constant(compileFunction(s)))
+	case contractStatement:
+		contract := &compiledContract{
+			name: s.name.Lexeme,
+		}
+		for _, f := range s.functions {
+			contract.functions = append(contract.functions, compileFunction(f))
+		}
+		for _, field := range s.state {
+			init := newCompiler()
+			init.expression(field.exp)
+			init.emit(opReturn, -1)
+			contract.fields = append(contract.fields, field.op.Lexeme)
+			contract.values = append(contract.values, init.chunk)
+		}
+		c.emit(opContract, c.constant(contract))
+		c.result(topLevel)
+	case returnStatement:
+		c.expression(s.value)
+		c.emit(opReturn, -1)
+	case expression:
+		//The initializer of a for loop is an expression used as a statement
+		c.expression(s)
+		c.emit(opPop, 0)
+	default:
+		panic(fmt.Sprintf("Unexpected statement %T", stmt))
+	}
+}
+
+func (c *compiler) result(topLevel bool) {
+	if topLevel {
+		c.emit(opOutput, 0)
+	} else {
+		c.emit(opPop, 0)
+	}
+}
+
+//block compiles a block in its own scope.
+//A nil return directly inside the block ends it, other values end the whole function.
+func (c *compiler) block(b blockStmt) {
+	var returns []int
+	c.emit(opPushScope, 0)
+	for _, s := range b.statements {
+		if r, ok := s.(returnStatement); ok {
+			c.expression(r.value)
+			returns = append(returns, c.emit(opReturn, 0))
+			continue
+		}
+		c.statement(s, false)
+	}
+	for _, r := range returns {
+		c.patch(r)
+	}
+	c.emit(opPopScope, 0)
+}
+
+func compileFunction(stmt funcStatement) *compiledFunction {
+	c := newCompiler()
+	c.block(stmt.body)
+
+	f := &compiledFunction{
+		name: stmt.name.Lexeme,
+		body: c.chunk,
+	}
+	for _, p := range stmt.params {
+		f.params = append(f.params, p.Lexeme)
+	}
+	return f
+}
+
+func (c *compiler) expression(exp expression) {
+	switch e := exp.(type) {
+	case literalExpression:
+		if e.value == nil {
+			c.emit(opNil, 0)
+			return
+		}
+		c.emit(opConstant, c.constant(e.value))
+	case variableExpression:
+		c.emitAt(opGetVar, c.name(e.op.Lexeme), e.op)
+	case assignExpression:
+		c.expression(e.exp)
+		c.emit(opSetVar, c.name(e.op.Lexeme))
+	case groupingExpression:
+		c.expression(e.exp)
+	case binaryExpression:
+		c.expression(e.left)
+		c.expression(e.right)
+		c.emitAt(opBinary, 0, e.op)
+	case unaryExpression:
+		c.expression(e.right)
+		if e.op.Type == TokenBang {
+			c.emit(opNot, 0)
+		} else {
+			c.emitAt(opNegate, 0, e.op)
+		}
+	case logicalExpression:
+		c.expression(e.left)
+		op := opJumpIfFalseOrPop
+		if e.op.Type == TokenOr {
+			op = opJumpIfTrueOrPop
+		}
+		end := c.emit(op, 0)
+		c.expression(e.right)
+		c.patch(end)
+	case callExpression:
+		tok := e.paren
+		if v, ok := e.callee.(variableExpression); ok {
+			tok = v.op
+		}
+		c.expression(e.callee)
+		c.emitAt(opCallee, 0, tok)
+		for _, arg := range e.args {
+			c.expression(arg)
+		}
+		c.emitAt(opCall, len(e.args), tok)
+	case listExpression:
+		for _, el := range e.elements {
+			c.expression(el)
+		}
+		c.emit(opList, len(e.elements))
+	case objectExpression:
+		for _, v := range e.values {
+			c.expression(v)
+		}
+		c.emit(opObject, c.constant(e.keys))
+	case indexExpression:
+		c.expression(e.object)
+		c.expression(e.index)
+		c.emitAt(opIndex, 0, e.bracket)
+	case indexAssignExpression:
+		c.expression(e.object)
+		c.expression(e.index)
+		c.expression(e.value)
+		c.emitAt(opIndexSet, 0, e.bracket)
+	case getExpression:
+		c.expression(e.object)
+		c.emitAt(opGet, c.name(e.name.Lexeme), e.name)
+	case setExpression:
+		c.expression(e.object)
+		c.emitAt(opSetTarget, c.name(e.name.Lexeme), e.name)
+		c.expression(e.value)
+		c.emitAt(opSet, c.name(e.name.Lexeme), e.name)
+	case transactionExpression:
+		c.emitAt(opTransaction, 0, e.keyword)
+	default:
+		panic(fmt.Sprintf("Unexpected expression %T", exp))
+	}
+}
@@ -0,0 +1,176 @@
+package uniris
+
+import (
+	"context"
+	"fmt"
+)
+
+//Contract is a deployed smart contract gathering its persistent state and its exported functions
+type Contract struct {
+	Name      string
+	env       *Environment
+	fields    []string
+	functions []string
+	gasUsed   uint64
+}
+
+//State returns a copy of the current value of each contract state variable
+func (c *Contract) State() map[string]Value {
+	state := make(map[string]Value, len(c.fields))
+	for _, f := range c.fields {
+		state[f] = copyValue(c.env.values[f], nil)
+	}
+	return state
+}
+
+//Fields returns the contract state variable names in declaration order
+func (c *Contract) Fields() []string {
+	return append([]string{}, c.fields...)
+}
+
+//Functions returns the contract exported function names in declaration order
+func (c *Contract) Functions() []string {
+	return append([]string{}, c.functions...)
+}
+
+//Call executes an exported function of the contract, updating its state
+func (c *Contract) Call(name string, args ...Value) (Value, error) {
+	return c.CallContext(context.Background(), name, args...)
+}
+
+//CallContext executes an exported function of the contract until the context is done
+func (c *Contract) CallContext(ctx context.Context, name string, args ...Value) (Value, error) {
+	f, err := c.function(name)
+	if err != nil {
+		return nil, err
+	}
+
+	exec := newExecution(ctx, c.env)
+	callEnv := NewEnvironment(c.env)
+	callEnv.exec = exec
+
+	//A failed call leaves the state as it was before the call
+	state := c.snapshot()
+	res, err := f.call(callEnv, args...)
+	if err != nil {
+		c.restore(state)
+		return nil, err
+	}
+	if _, err := exec.commit(); err != nil {
+		c.restore(state)
+		return nil, err
+	}
+	c.gasUsed = exec.gas.used
+	return res, nil
+}
+
+//snapshot copies the state variables so they can be restored
+func (c *Contract) snapshot() map[string]Value {
+	copies := make(map[Value]Value, 0)
+	state := make(map[string]Value, len(c.fields))
+	for _, f := range c.fields {
+		state[f] = copyValue(c.env.values[f], copies)
+	}
+	return state
+}
+
+//restore sets the state variables back to a snapshot
+func (c *Contract) restore(state map[string]Value) {
+	for f, v := range state {
+		c.env.values[f] = v
+	}
+}
+
+//GasUsed returns the gas consumed by the last successful call
+func (c *Contract) GasUsed() uint64 {
+	return c.gasUsed
+}
+
+func (c *Contract) function(name string) (callable, error) {
+	for _, fn := range c.functions {
+		if fn == name {
+			return c.env.values[name].(callable), nil
+		}
+	}
+	return nil, fmt.Errorf("Contract %s has no function %s", c.Name, name)
+}
+
+//get reads a state variable or a function from the script.
+//Lists and objects are copied, the state is only changed by the contract functions.
+func (c *Contract) get(name string) (Value, error) {
+	if v, exist := c.env.values[name]; exist {
+		return copyValue(v, nil), nil
+	}
+	return nil, fmt.Errorf("Contract %s has no member %s", c.Name, name)
+}
+
+func (c *Contract) String() string {
+	return fmt.Sprintf("contract %s", c.Name)
+}
+
+//newContract creates an empty contract whose environment encloses the declaration one
+func newContract(env *Environment, name string) *Contract {
+	return &Contract{
+		Name: name,
+		env:  NewEnvironment(env),
+	}
+}
+
+//addFunction exports a function executed inside the contract environment
+func (c *Contract) addFunction(name string, f callable) {
+	c.env.define(name, boundFunction{
+		callable: f,
+		contract: c,
+	})
+	c.functions = append(c.functions, name)
+}
+
+//addField declares a state variable
+func (c *Contract) addField(name string, value Value) {
+	c.env.define(name, value)
+	c.fields = append(c.fields, name)
+}
+
+//initField declares a state variable with its stored value, evaluating its initializer only when the store has none
+func (c *Contract) initField(exec *execution, name string, init func() (Value, error)) error {
+	val, exist, err := exec.storedField(c.Name, name)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		if val, err = init(); err != nil {
+			return err
+		}
+	}
+	c.addField(name, val)
+	return nil
+}
+
+//boundFunction is a function executed inside the environment of its contract
+type boundFunction struct {
+	callable
+	contract *Contract
+}
+
+func (f boundFunction) call(env *Environment, args ...Value) (Value, error) {
+	//The declaration scope is used but the run state is the caller's one
+	scope := NewEnvironment(f.contract.env)
+	if env != nil {
+		scope.exec = env.exec
+	}
+	if err := scope.exec.track(f.contract, true); err != nil {
+		return nil, err
+	}
+
+	//The arguments and the result are copied so the state never shares a list or an object with the caller
+	copies := make(map[Value]Value, 0)
+	copied := make([]Value, len(args))
+	for i, arg := range args {
+		copied[i] = copyValue(arg, copies)
+	}
+	res, err := f.callable.call(scope, copied...)
+	if err != nil {
+		return nil, err
+	}
+	return copyValue(res, nil), nil
+}
@@ -0,0 +1,184 @@
+package uniris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const apostilleContract = `
+contract Apostille {
+    isApostilled = false
+    refugeeID = ""
+
+    function setApostille(id) {
+        if isApostilled == false {
+            isApostilled = true
+            refugeeID = id
+        }
+    }
+
+    function getRefugeeID() {
+        return refugeeID
+    }
+}
+`
+
+func TestDeployContract(t *testing.T) {
+	res, err := Interpret(apostilleContract, nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, res.Contract)
+
+	c := res.Contract
+	assert.Equal(t, "Apostille", c.Name)
+	assert.Equal(t, []string{"isApostilled", "refugeeID"}, c.Fields())
+	assert.Equal(t, []string{"setApostille", "getRefugeeID"}, c.Functions())
+	assert.Equal(t, map[string]Value{
+		"isApostilled": Bool(false),
+		"refugeeID":    String(""),
+	}, c.State())
+}
+
+func TestCallContract(t *testing.T) {
+	res, err := Interpret(apostilleContract, nil)
+	assert.Nil(t, err)
+	c := res.Contract
+
+	_, err = c.Call("setApostille", String("123"))
+	assert.Nil(t, err)
+	_, err = c.Call("setApostille", String("456"))
+	assert.Nil(t, err)
+
+	val, err := c.Call("getRefugeeID")
+	assert.Nil(t, err)
+	assert.Equal(t, String("123"), val)
+	assert.Equal(t, Bool(true), c.State()["isApostilled"])
+
+	_, err = c.Call("unknown")
+	assert.EqualError(t, err, "Contract Apostille has no function unknown")
+}
+
+func TestContractMemberAccess(t *testing.T) {
+	res, err := Interpret(apostilleContract+`
+Apostille.setApostille("123")
+Apostille.refugeeID
+`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "123\n", res.Output)
+
+	_, err = Interpret(apostilleContract+`
+Apostille.refugeeID = "456"
+`, nil)
+	assert.EqualError(t, err, "Runtime error at refugeeID of line 18, column 11 - Contract state can only be changed by its functions, cannot set refugeeID")
+}
+
+func TestContractStateIsScoped(t *testing.T) {
+	_, err := Interpret(apostilleContract+`
+print refugeeID
+`, nil)
+	assert.EqualError(t, err, "Runtime error at refugeeID of line 18, column 7 - Undefined variable refugeeID")
+}
+
+func TestContractStateIsCopied(t *testing.T) {
+	const registry = `
+contract Registry {
+    ids = ["a"]
+    owner = {name: "alice"}
+
+    function list() {
+        return ids
+    }
+    function add(id) {
+        push(ids, id)
+    }
+    function keep(o) {
+        owner = o
+    }
+}
+`
+	code := registry + `
+Registry.list()[0] = "x"
+push(Registry.ids, "y")
+Registry.owner.name = "mallory"
+o = {name: "bob"}
+Registry.keep(o)
+o.name = "eve"
+Registry.list()
+Registry.owner
+`
+	res, err, vmRes, vmErr := runBoth(t, code)
+	assert.Nil(t, err)
+	assert.Nil(t, vmErr)
+	assert.Equal(t, "[\"a\"]\n{\"name\": \"bob\"}\n", res.Output)
+	assert.Equal(t, res.Output, vmRes.Output)
+
+	res, err = Interpret(registry, nil)
+	assert.Nil(t, err)
+	c := res.Contract
+	ids, err := c.Call("list")
+	assert.Nil(t, err)
+	ids.(*list).elements[0] = String("x")
+	c.State()["ids"].(*list).elements[0] = String("y")
+	_, err = c.Call("add", String("b"))
+	assert.Nil(t, err)
+	assert.True(t, Equal(newList(String("a"), String("b")), c.State()["ids"]))
+}
+
+func TestCopyValue(t *testing.T) {
+	shared := newList(Int(1))
+	o := newObject()
+	o.set(String("a"), shared)
+	o.set(String("b"), shared)
+	o.set(String("self"), o)
+
+	cp := copyValue(o, nil).(*object)
+	assert.True(t, Equal(o, cp))
+	assert.True(t, cp.lookup(String("a")) == cp.lookup(String("b")))
+	assert.True(t, cp.lookup(String("self")) == cp)
+	assert.False(t, cp.lookup(String("a")) == shared)
+	assert.Equal(t, Int(2), copyValue(Int(2), nil))
+}
+
+func TestContractStateUnchangedByFailedCalls(t *testing.T) {
+	const spender = `
+contract Spender {
+    n = 0
+    xs = []
+
+    function spend(amount) {
+        n = n + 1
+        push(xs, 1)
+        send("alice", amount)
+    }
+}
+`
+	ledger := NewMemoryLedger(map[string]Decimal{"contract": MustParseDecimal("10")})
+	res, err := NewInterpreter(WithLedger(ledger, "contract")).Run(spender)
+	assert.Nil(t, err)
+	c := res.Contract
+
+	for i := 0; i < 2; i++ {
+		_, err = c.Call("spend", Int(20))
+		assert.EqualError(t, err, "Runtime error at send of line 9, column 9 - Insufficient balance: 10 available, 20 requested")
+		assert.True(t, Equal(newList(), c.State()["xs"]))
+		assert.Equal(t, Int(0), c.State()["n"])
+	}
+
+	//The transfers refused by the ledger when committing are failed calls too
+	res, err = NewInterpreter(WithLedger(refusingLedger{ledger}, "contract")).Run(spender)
+	assert.Nil(t, err)
+	c = res.Contract
+	for i := 0; i < 2; i++ {
+		_, err = c.Call("spend", Int(1))
+		assert.EqualError(t, err, "Ledger unavailable")
+		assert.True(t, Equal(newList(), c.State()["xs"]))
+		assert.Equal(t, Int(0), c.State()["n"])
+	}
+
+	res, err = NewInterpreter(WithLedger(ledger, "contract")).Run(spender)
+	assert.Nil(t, err)
+	_, err = res.Contract.Call("spend", Int(1))
+	assert.Nil(t, err)
+	assert.True(t, Equal(newList(Int(1)), res.Contract.State()["xs"]))
+	assert.Equal(t, Int(1), res.Contract.State()["n"])
+}
@@ -0,0 +1,148 @@
+package uniris
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+//signatureCurves verifies a signature of a message for a public key on a given curve
+var signatureCurves = map[string]func(publicKey []byte, message []byte, signature []byte) (bool, error){
+	"ed25519": verifyEd25519,
+	"p256":    verifyP256,
+}
+
+func verifyEd25519(publicKey []byte, message []byte, signature []byte) (bool, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false, fmt.Errorf("Invalid ed25519 public key: expected %d bytes, got %d", ed25519.PublicKeySize, len(publicKey))
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return false, nil
+	}
+	return ed25519.Verify(ed25519.PublicKey(publicKey), message, signature), nil
+}
+
+//verifyP256 checks an ASN.1 DER ECDSA signature of the SHA-256 digest of the message.
+//The public key is a SEC 1 encoded point, compressed or not.
+func verifyP256(publicKey []byte, message []byte, signature []byte) (bool, error) {
+	curve := elliptic.P256()
+
+	x, y := elliptic.Unmarshal(curve, publicKey)
+	if x == nil {
+		x, y = elliptic.UnmarshalCompressed(curve, publicKey)
+	}
+	if x == nil {
+		return false, fmt.Errorf("Invalid p256 public key: not a point of the curve in SEC 1 encoding (%d bytes)", len(publicKey))
+	}
+
+	digest := sha256.Sum256(message)
+	pub := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	return ecdsa.VerifyASN1(pub, digest[:], signature), nil
+}
+
+//aesGCM builds an AES-256-GCM cipher from a 32 bytes key
+func aesGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("Invalid AES-256 key: expected 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func aesEncrypt(key []byte, nonce []byte, plaintext []byte) ([]byte, error) {
+	aead, err := aesGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("Invalid nonce: expected %d bytes, got %d", aead.NonceSize(), len(nonce))
+	}
+	return aead.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func aesDecrypt(key []byte, nonce []byte, ciphertext []byte) ([]byte, error) {
+	aead, err := aesGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("Invalid nonce: expected %d bytes, got %d", aead.NonceSize(), len(nonce))
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("Cannot decrypt: message authentication failed")
+	}
+	return plaintext, nil
+}
+
+//ECIES on P-256: an ephemeral key agrees a secret with the recipient key,
+//HKDF-SHA256 derives an AES-256-GCM key from it and the ciphertext is prefixed
+//with the uncompressed ephemeral public key.
+//As every key is used once, the nonce is always zero.
+const eciesInfo = "uniris-ecies-p256"
+
+func eciesEncrypt(publicKey []byte, plaintext []byte) ([]byte, error) {
+	curve := ecdh.P256()
+	if len(publicKey) == 33 {
+		x, y := elliptic.UnmarshalCompressed(elliptic.P256(), publicKey)
+		if x != nil {
+			publicKey = elliptic.Marshal(elliptic.P256(), x, y)
+		}
+	}
+	pub, err := curve.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid p256 public key: not a point of the curve in SEC 1 encoding (%d bytes)", len(publicKey))
+	}
+	ephemeral, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	key, err := eciesKey(ephemeral, pub)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := aesEncrypt(key, make([]byte, 12), plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(ephemeral.PublicKey().Bytes(), ciphertext...), nil
+}
+
+func eciesDecrypt(privateKey []byte, ciphertext []byte) ([]byte, error) {
+	curve := ecdh.P256()
+	priv, err := curve.NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid p256 private key: %s", err)
+	}
+	if len(ciphertext) < 65 {
+		return nil, errors.New("Cannot decrypt: ciphertext too short")
+	}
+	ephemeral, err := curve.NewPublicKey(ciphertext[:65])
+	if err != nil {
+		return nil, errors.New("Cannot decrypt: invalid ephemeral public key")
+	}
+	key, err := eciesKey(priv, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	return aesDecrypt(key, make([]byte, 12), ciphertext[65:])
+}
+
+func eciesKey(priv *ecdh.PrivateKey, pub *ecdh.PublicKey) ([]byte, error) {
+	shared, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+	return hkdf.Key(sha256.New, shared, nil, eciesInfo, 32)
+}
@@ -0,0 +1,155 @@
+package uniris
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyEd25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	sig := ed25519.Sign(priv, []byte("hello"))
+
+	val, err := verifyFunc{}.call(nil, String(hex.EncodeToString(pub)), String("hello"), String(hex.EncodeToString(sig)), String("ed25519"))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(true), val)
+
+	val, err = verifyFunc{}.call(nil, String(hex.EncodeToString(pub)), String("hell0"), String(hex.EncodeToString(sig)), String("ed25519"))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(false), val)
+
+	val, err = verifyCurveFunc{curve: "ed25519"}.call(nil, String(hex.EncodeToString(pub)), String("hello"), String("00"), String("ed25519"))
+	assert.EqualError(t, err, "verify_ed25519 expects 3 arguments, got 4")
+
+	val, err = verifyCurveFunc{curve: "ed25519"}.call(nil, String(hex.EncodeToString(pub)), String("hello"), String("00"))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(false), val)
+
+	_, err = verifyFunc{}.call(nil, String("0102"), String("hello"), String(hex.EncodeToString(sig)), String("ed25519"))
+	assert.EqualError(t, err, "Invalid ed25519 public key: expected 32 bytes, got 2")
+
+	_, err = verifyFunc{}.call(nil, String("xyz"), String("hello"), String(hex.EncodeToString(sig)), String("ed25519"))
+	assert.EqualError(t, err, "Invalid public key: encoding/hex: invalid byte: U+0078 'x'")
+}
+
+func TestVerifyP256(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	digest := sha256.Sum256([]byte("hello"))
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	assert.Nil(t, err)
+
+	uncompressed := hex.EncodeToString(elliptic.Marshal(elliptic.P256(), priv.X, priv.Y))
+	compressed := hex.EncodeToString(elliptic.MarshalCompressed(elliptic.P256(), priv.X, priv.Y))
+
+	for _, pub := range []string{uncompressed, compressed} {
+		val, err := verifyFunc{}.call(nil, String(pub), String("hello"), String(hex.EncodeToString(sig)), String("p256"))
+		assert.Nil(t, err)
+		assert.Equal(t, Bool(true), val)
+
+		val, err = verifyFunc{}.call(nil, String(pub), String("hell0"), String(hex.EncodeToString(sig)), String("p256"))
+		assert.Nil(t, err)
+		assert.Equal(t, Bool(false), val)
+	}
+
+	_, err = verifyFunc{}.call(nil, String("04"+uncompressed[4:]), String("hello"), String(hex.EncodeToString(sig)), String("p256"))
+	assert.EqualError(t, err, "Invalid p256 public key: not a point of the curve in SEC 1 encoding (64 bytes)")
+}
+
+func TestVerifyUnsupportedCurve(t *testing.T) {
+	_, err := verifyFunc{}.call(nil, String(""), String("hello"), String(""), String("secp256k1"))
+	assert.EqualError(t, err, "Unsupported signature curve secp256k1, expected one of ed25519, p256")
+}
+
+func TestInterpretVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	sig := ed25519.Sign(priv, []byte("apostille"))
+
+	env := NewEnvironment(nil)
+	env.Set("agentPublicKey", String(hex.EncodeToString(pub)))
+	env.Set("signature", String(hex.EncodeToString(sig)))
+	res, err := Interpret(`verify(agentPublicKey, "apostille", signature, "ed25519")`, env)
+	assert.Nil(t, err)
+	assert.Equal(t, "true\n", res.Output)
+}
+
+func TestAESEncrypt(t *testing.T) {
+	//Test case 14 of the GCM specification
+	ciphertext, err := aesEncrypt(make([]byte, 32), make([]byte, 12), make([]byte, 16))
+	assert.Nil(t, err)
+	assert.Equal(t, "cea7403d4d606b6e074ec5d3baf39d18d0d1c8a799996bf0265b98b5d48ab919", hex.EncodeToString(ciphertext))
+
+	plaintext, err := aesDecrypt(make([]byte, 32), make([]byte, 12), ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, make([]byte, 16), plaintext)
+
+	ciphertext[0] ^= 1
+	_, err = aesDecrypt(make([]byte, 32), make([]byte, 12), ciphertext)
+	assert.EqualError(t, err, "Cannot decrypt: message authentication failed")
+
+	_, err = aesEncrypt(make([]byte, 16), make([]byte, 12), nil)
+	assert.EqualError(t, err, "Invalid AES-256 key: expected 32 bytes, got 16")
+
+	_, err = aesEncrypt(make([]byte, 32), make([]byte, 8), nil)
+	assert.EqualError(t, err, "Invalid nonce: expected 12 bytes, got 8")
+}
+
+func TestECIES(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	uncompressed := elliptic.Marshal(elliptic.P256(), priv.X, priv.Y)
+	compressed := elliptic.MarshalCompressed(elliptic.P256(), priv.X, priv.Y)
+	scalar := priv.D.FillBytes(make([]byte, 32))
+
+	for _, pub := range [][]byte{uncompressed, compressed} {
+		ciphertext, err := eciesEncrypt(pub, []byte("refugee 123"))
+		assert.Nil(t, err)
+
+		plaintext, err := eciesDecrypt(scalar, ciphertext)
+		assert.Nil(t, err)
+		assert.Equal(t, "refugee 123", string(plaintext))
+	}
+
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ciphertext, _ := eciesEncrypt(uncompressed, []byte("refugee 123"))
+	_, err = eciesDecrypt(other.D.FillBytes(make([]byte, 32)), ciphertext)
+	assert.EqualError(t, err, "Cannot decrypt: message authentication failed")
+
+	_, err = eciesEncrypt([]byte{4, 1, 2}, []byte("refugee 123"))
+	assert.EqualError(t, err, "Invalid p256 public key: not a point of the curve in SEC 1 encoding (3 bytes)")
+}
+
+func TestInterpretEncryption(t *testing.T) {
+	priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	env := NewEnvironment(nil)
+	env.SetSecret("storage", make([]byte, 32))
+	env.SetSecret("agent", priv.D.FillBytes(make([]byte, 32)))
+	env.Set("agentPublicKey", String(hex.EncodeToString(elliptic.Marshal(elliptic.P256(), priv.X, priv.Y))))
+
+	res, err := Interpret(`
+nonce = "000000000000000000000001"
+secret = encrypt("refugee 123", "storage", nonce)
+decrypt(secret, "storage", nonce)
+ecies_decrypt(ecies_encrypt("birth date", agentPublicKey), "agent")
+`, env)
+	assert.Nil(t, err)
+	assert.Equal(t, "refugee 123\nbirth date\n", res.Output)
+
+	_, err = Interpret(`encrypt("refugee 123", "unknown", "000000000000000000000001")`, env)
+	assert.EqualError(t, err, "Runtime error at encrypt of line 1, column 1 - Undefined secret unknown")
+}
+
+func TestSecretIsNotReadable(t *testing.T) {
+	env := NewEnvironment(nil)
+	env.SetSecret("storage", make([]byte, 32))
+	_, err := Interpret(`print storage`, env)
+	assert.EqualError(t, err, "Runtime error at storage of line 1, column 7 - Undefined variable storage")
+}
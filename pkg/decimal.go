@@ -0,0 +1,274 @@
+package uniris
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	//DecimalMaxScale is the maximum number of fractional digits of a decimal.
+	//Multiplications and divisions are rounded half to even to this scale.
+	DecimalMaxScale = 18
+
+	//DecimalMaxDigits is the maximum number of significant digits of a decimal.
+	//Operations producing more digits fail with an overflow error.
+	DecimalMaxDigits = 38
+)
+
+var decimalMaxUnscaled = new(big.Int).Exp(big.NewInt(10), big.NewInt(DecimalMaxDigits), nil)
+
+//Decimal is an exact decimal number used for the token amounts: unscaled * 10^-scale
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+//NewDecimal creates the decimal unscaled * 10^-scale
+func NewDecimal(unscaled int64, scale int32) (Decimal, error) {
+	if scale < 0 || scale > DecimalMaxScale {
+		return Decimal{}, fmt.Errorf("Decimal scale must be between 0 and %d, got %d", DecimalMaxScale, scale)
+	}
+	return newDecimal(big.NewInt(unscaled), scale)
+}
+
+//ParseDecimal parses a decimal written as [-]digits[.digits]
+func ParseDecimal(s string) (Decimal, error) {
+	digits := strings.TrimPrefix(s, "-")
+	parts := strings.Split(digits, ".")
+	if len(parts) > 2 || parts[0] == "" || (len(parts) == 2 && parts[1] == "") {
+		return Decimal{}, fmt.Errorf("Invalid decimal %s", s)
+	}
+	for _, p := range parts {
+		for _, c := range p {
+			if c < '0' || c > '9' {
+				return Decimal{}, fmt.Errorf("Invalid decimal %s", s)
+			}
+		}
+	}
+
+	var scale int32
+	if len(parts) == 2 {
+		if len(parts[1]) > DecimalMaxScale {
+			return Decimal{}, fmt.Errorf("Decimal %s exceeds %d fractional digits", s, DecimalMaxScale)
+		}
+		scale = int32(len(parts[1]))
+	}
+	unscaled, _ := new(big.Int).SetString(strings.Join(parts, ""), 10)
+	if strings.HasPrefix(s, "-") {
+		unscaled.Neg(unscaled)
+	}
+	return newDecimal(unscaled, scale)
+}
+
+//MustParseDecimal parses a decimal and panics if it is invalid
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+//decimalFromFloat converts a float using its shortest exact representation
+func decimalFromFloat(f float64) (Decimal, error) {
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+//newDecimal rounds to the maximum scale, removes the trailing zeros and checks the overflow
+func newDecimal(unscaled *big.Int, scale int32) (Decimal, error) {
+	if scale > DecimalMaxScale {
+		unscaled = divRoundHalfEven(unscaled, pow10(scale-DecimalMaxScale))
+		scale = DecimalMaxScale
+	}
+
+	ten := big.NewInt(10)
+	unscaled = new(big.Int).Set(unscaled)
+	r := new(big.Int)
+	for scale > 0 {
+		q, m := new(big.Int).QuoRem(unscaled, ten, r)
+		if m.Sign() != 0 {
+			break
+		}
+		unscaled = q
+		scale--
+	}
+
+	if new(big.Int).Abs(unscaled).Cmp(decimalMaxUnscaled) >= 0 {
+		return Decimal{}, fmt.Errorf("Decimal overflow: more than %d significant digits", DecimalMaxDigits)
+	}
+	return Decimal{
+		unscaled: unscaled,
+		scale:    scale,
+	}, nil
+}
+
+func (d Decimal) value() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+//Sign returns -1, 0 or 1 depending on the sign of the decimal
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+//Cmp compares two decimals and returns -1, 0 or 1
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := align(d, o)
+	return a.Cmp(b)
+}
+
+//Add returns d + o
+func (d Decimal) Add(o Decimal) (Decimal, error) {
+	a, b := align(d, o)
+	return newDecimal(new(big.Int).Add(a, b), maxScale(d, o))
+}
+
+//Sub returns d - o
+func (d Decimal) Sub(o Decimal) (Decimal, error) {
+	a, b := align(d, o)
+	return newDecimal(new(big.Int).Sub(a, b), maxScale(d, o))
+}
+
+//Mul returns d * o rounded to DecimalMaxScale
+func (d Decimal) Mul(o Decimal) (Decimal, error) {
+	return newDecimal(new(big.Int).Mul(d.value(), o.value()), d.scale+o.scale)
+}
+
+//Div returns d / o rounded to DecimalMaxScale
+func (d Decimal) Div(o Decimal) (Decimal, error) {
+	if o.Sign() == 0 {
+		return Decimal{}, fmt.Errorf("Division by zero")
+	}
+	num := new(big.Int).Mul(d.value(), pow10(DecimalMaxScale+o.scale-d.scale))
+	return newDecimal(divRoundHalfEven(num, o.value()), DecimalMaxScale)
+}
+
+//Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{
+		unscaled: new(big.Int).Neg(d.value()),
+		scale:    d.scale,
+	}
+}
+
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.value()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= int(d.scale) {
+		digits = strings.Repeat("0", int(d.scale)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func align(a Decimal, b Decimal) (*big.Int, *big.Int) {
+	scale := maxScale(a, b)
+	return new(big.Int).Mul(a.value(), pow10(scale-a.scale)), new(big.Int).Mul(b.value(), pow10(scale-b.scale))
+}
+
+func maxScale(a Decimal, b Decimal) int32 {
+	if a.scale > b.scale {
+		return a.scale
+	}
+	return b.scale
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func divRoundHalfEven(num *big.Int, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	cmp := new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(new(big.Int).Abs(den))
+	if cmp > 0 || (cmp == 0 && q.Bit(0) == 1) {
+		if num.Sign()*den.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+//toDecimal converts an operand of a decimal operation, integers are promoted
+func toDecimal(v Value) (Decimal, error) {
+	switch n := v.(type) {
+	case Decimal:
+		return n, nil
+	case Int:
+		return newDecimal(big.NewInt(int64(n)), 0)
+	case Float:
+		return Decimal{}, fmt.Errorf("Cannot mix decimal and float numbers, convert %v with decimal()", n)
+	default:
+		return Decimal{}, fmt.Errorf("Operand must be a number, got %v", v)
+	}
+}
+
+func isDecimalOperand(v Value) bool {
+	return KindOf(v) == KindDecimal || isNumber(v)
+}
+
+//toAmount converts a transfer amount, floats are converted using their shortest representation
+func toAmount(v Value) (Decimal, error) {
+	if f, ok := v.(Float); ok {
+		return decimalFromFloat(float64(f))
+	}
+	return toDecimal(v)
+}
+
+func decimalOperation(op token, left Value, right Value) (Value, error) {
+	if !isDecimalOperand(left) || !isDecimalOperand(right) {
+		return nil, operandError(op, left, right)
+	}
+	l, err := toDecimal(left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := toDecimal(right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Type {
+	case TokenPlus:
+		return decimalResult(l.Add(r))
+	case TokenMinus:
+		return decimalResult(l.Sub(r))
+	case TokenStar:
+		return decimalResult(l.Mul(r))
+	case TokenSlash:
+		return decimalResult(l.Div(r))
+	case TokenGreater:
+		return Bool(l.Cmp(r) > 0), nil
+	case TokenGreaterEqual:
+		return Bool(l.Cmp(r) >= 0), nil
+	case TokenLess:
+		return Bool(l.Cmp(r) < 0), nil
+	case TokenLessEqual:
+		return Bool(l.Cmp(r) <= 0), nil
+	default:
+		return nil, fmt.Errorf("Operator %s is not supported on decimals", op.Lexeme)
+	}
+}
+
+//decimalResult turns the outcome of a decimal operation into a value
+func decimalResult(d Decimal, err error) (Value, error) {
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,89 @@
+package uniris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	d, err := ParseDecimal("12.340")
+	assert.Nil(t, err)
+	assert.Equal(t, "12.34", d.String())
+
+	d, err = ParseDecimal("-0.05")
+	assert.Nil(t, err)
+	assert.Equal(t, "-0.05", d.String())
+
+	_, err = ParseDecimal("1.")
+	assert.EqualError(t, err, "Invalid decimal 1.")
+	_, err = ParseDecimal("1e5")
+	assert.EqualError(t, err, "Invalid decimal 1e5")
+	_, err = ParseDecimal("0.0000000000000000001")
+	assert.EqualError(t, err, "Decimal 0.0000000000000000001 exceeds 18 fractional digits")
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a := MustParseDecimal("0.1")
+	b := MustParseDecimal("0.2")
+
+	sum, err := a.Add(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.3", sum.String())
+	assert.Equal(t, 0, sum.Cmp(MustParseDecimal("0.30")))
+
+	diff, err := a.Sub(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "-0.1", diff.String())
+
+	prod, err := MustParseDecimal("1.5").Mul(MustParseDecimal("2"))
+	assert.Nil(t, err)
+	assert.Equal(t, "3", prod.String())
+
+	quo, err := MustParseDecimal("1").Div(MustParseDecimal("3"))
+	assert.Nil(t, err)
+	assert.Equal(t, "0.333333333333333333", quo.String())
+
+	quo, err = MustParseDecimal("2").Div(MustParseDecimal("3"))
+	assert.Nil(t, err)
+	assert.Equal(t, "0.666666666666666667", quo.String())
+
+	_, err = a.Div(MustParseDecimal("0"))
+	assert.EqualError(t, err, "Division by zero")
+}
+
+func TestDecimalRounding(t *testing.T) {
+	//Half to even at the 18th fractional digit
+	d, err := MustParseDecimal("0.000000000000000005").Mul(MustParseDecimal("0.5"))
+	assert.Nil(t, err)
+	assert.Equal(t, "0.000000000000000002", d.String())
+
+	d, err = MustParseDecimal("0.000000000000000015").Mul(MustParseDecimal("0.5"))
+	assert.Nil(t, err)
+	assert.Equal(t, "0.000000000000000008", d.String())
+}
+
+func TestDecimalOverflow(t *testing.T) {
+	big := MustParseDecimal("99999999999999999999999999999999999999")
+	_, err := big.Add(MustParseDecimal("1"))
+	assert.EqualError(t, err, "Decimal overflow: more than 38 significant digits")
+}
+
+func TestInterpretDecimal(t *testing.T) {
+	res, err := Interpret(`
+0.1d + 0.2d
+0.1d + 0.2d == 0.3d
+10d / 4d
+1.5d * 2d
+1d > -2.5d
+"total: " + 1.25d
+decimal("0.1") + decimal(0.2)
+`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.3\ntrue\n2.5\n3\ntrue\ntotal: 1.25\n0.3\n", res.Output)
+}
+
+func TestInterpretDecimalMixedWithFloat(t *testing.T) {
+	_, err := Interpret("0.1d + 0.2", nil)
+	assert.EqualError(t, err, "Runtime error at + of line 1, column 6 - Cannot mix decimal and float numbers, convert 0.2 with decimal()")
+}
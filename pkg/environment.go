@@ -2,47 +2,293 @@ package uniris
 
 import (
 	"fmt"
+	"io"
+	"os"
 )
 
 //Environment contains the values and inner values storage for the interpreter context (variables, functions)
 type Environment struct {
-	enclosing *Environment
-	values    map[string]interface{}
+	enclosing     *Environment
+	values        map[string]Value
+	transaction   *Transaction
+	ledger        Ledger
+	account       string
+	stateStore    StateStore
+	stateID       string
+	secrets       map[string][]byte
+	gasLimit      uint64
+	clock         func() int64
+	deterministic bool
+	output        io.Writer
+	exec          *execution
 }
 
 //NewEnvironment creates a new interpreter environment
 func NewEnvironment(enclosing *Environment) *Environment {
-	return &Environment{
-		values:    make(map[string]interface{}, 0),
+	env := &Environment{
+		values:    make(map[string]Value, 0),
 		enclosing: enclosing,
 	}
+	if enclosing != nil {
+		env.exec = enclosing.exec
+	}
+	return env
 }
 
-func (env *Environment) Set(name string, value interface{}) {
-	if env.enclosing != nil {
-		_, err := env.enclosing.Get(name)
-		if err != nil {
-			if err.Error() == fmt.Sprintf("Undefined variable %s", name) {
-				env.values[name] = value
-				return
+//Set assigns a variable in the outermost environment holding it, or defines it in this one
+func (env *Environment) Set(name string, value Value) {
+	scope, _ := env.resolve(name, true)
+	if scope == nil {
+		scope = env
+	}
+	scope.values[name] = value
+}
+
+//define creates the variable in this environment even if an enclosing one already holds it
+func (env *Environment) define(name string, value Value) {
+	env.values[name] = value
+}
+
+func (env *Environment) Get(name string) (Value, error) {
+	scope, _ := env.resolve(name, false)
+	if scope == nil {
+		return nil, fmt.Errorf("Undefined variable %s", name)
+	}
+	return scope.values[name], nil
+}
+
+//resolve returns the innermost or the outermost environment holding a variable
+//and the number of enclosing environments walked through to find it
+func (env *Environment) resolve(name string, outermost bool) (*Environment, int) {
+	var scope *Environment
+	hops := 0
+	for e := env; e != nil; e = e.enclosing {
+		if _, exist := e.values[name]; exist {
+			scope = e
+			if !outermost {
+				break
 			}
-			panic(err)
 		}
-		env.enclosing.Set(name, value)
-	} else {
-		env.values[name] = value
+		if e.enclosing != nil {
+			hops++
+		}
+	}
+	return scope, hops
+}
+
+//lookup reads a variable for a script, consuming gas for each environment walked through
+func (env *Environment) lookup(name string) (Value, error) {
+	scope, hops := env.resolve(name, false)
+	if err := env.consumeGas(uint64(hops) * gasPerScope); err != nil {
+		return nil, err
+	}
+	if scope == nil {
+		return nil, fmt.Errorf("Undefined variable %s", name)
+	}
+	return scope.values[name], nil
+}
+
+//assign sets a variable for a script as Set does, consuming gas for each environment walked through
+func (env *Environment) assign(name string, value Value) error {
+	scope, hops := env.resolve(name, true)
+	if err := env.consumeGas(uint64(hops) * gasPerScope); err != nil {
+		return err
+	}
+	if scope == nil {
+		scope = env
+	}
+	scope.values[name] = value
+	return nil
+}
+
+//SetTransaction defines the transaction triggering the contract execution
+func (env *Environment) SetTransaction(tx Transaction) {
+	env.transaction = &tx
+}
+
+func (env *Environment) lookupTransaction() *Transaction {
+	if env.transaction != nil {
+		return env.transaction
+	}
+	if env.enclosing != nil {
+		return env.enclosing.lookupTransaction()
+	}
+	return nil
+}
+
+//SetLedger defines the ledger used to send IRIS from the account of the contract
+func (env *Environment) SetLedger(ledger Ledger, account string) {
+	env.ledger = ledger
+	env.account = account
+}
+
+func (env *Environment) lookupLedger() (Ledger, string) {
+	if env.ledger != nil {
+		return env.ledger, env.account
+	}
+	if env.enclosing != nil {
+		return env.enclosing.lookupLedger()
+	}
+	return nil, ""
+}
+
+//SetStateStore defines the store keeping the contract state variables between the executions.
+//The id identifies the deployment owning the state, such as its contract address, and prefixes its keys.
+func (env *Environment) SetStateStore(store StateStore, id string) {
+	env.stateStore = store
+	env.stateID = id
+}
+
+func (env *Environment) lookupStateStore() (StateStore, string) {
+	if env.stateStore != nil {
+		return env.stateStore, env.stateID
+	}
+	if env.enclosing != nil {
+		return env.enclosing.lookupStateStore()
+	}
+	return nil, ""
+}
+
+//SetSecret defines a key the scripts can use by name without reading it
+func (env *Environment) SetSecret(name string, key []byte) {
+	if env.secrets == nil {
+		env.secrets = make(map[string][]byte, 0)
+	}
+	env.secrets[name] = append([]byte{}, key...)
+}
+
+func (env *Environment) lookupSecret(name string) ([]byte, error) {
+	if key, exist := env.secrets[name]; exist {
+		return key, nil
 	}
+	if env.enclosing != nil {
+		return env.enclosing.lookupSecret(name)
+	}
+	return nil, fmt.Errorf("Undefined secret %s", name)
+}
+
+//SetGasLimit defines the maximum gas an execution can use, zero means unlimited
+func (env *Environment) SetGasLimit(limit uint64) {
+	env.gasLimit = limit
+}
+
+func (env *Environment) lookupGasLimit() uint64 {
+	if env.gasLimit > 0 {
+		return env.gasLimit
+	}
+	if env.enclosing != nil {
+		return env.enclosing.lookupGasLimit()
+	}
+	return 0
+}
+
+//useGas charges the gas of a node evaluation to the current execution
+func (env *Environment) useGas(kind gasKind) error {
+	if env == nil || env.exec == nil {
+		return nil
+	}
+	return env.exec.gas.use(kind)
+}
+
+//consumeGas uses an amount of gas computed from the size of the data handled
+func (env *Environment) consumeGas(amount uint64) error {
+	if env == nil || env.exec == nil {
+		return nil
+	}
+	return env.exec.gas.consume(amount)
+}
+
+//useItemsGas consumes the gas of an operation on a number of list elements or string bytes
+func (env *Environment) useItemsGas(items int) error {
+	return env.consumeGas(uint64(items) * gasPerItem)
+}
+
+//enterCall counts a nested function call, failing beyond maxCallDepth
+func (env *Environment) enterCall() error {
+	if env == nil || env.exec == nil {
+		return nil
+	}
+	if env.exec.depth >= maxCallDepth {
+		return fmt.Errorf("Maximum call depth of %d exceeded", maxCallDepth)
+	}
+	env.exec.depth++
+	return nil
+}
+
+//leaveCall ends a call counted by enterCall
+func (env *Environment) leaveCall() {
+	if env == nil || env.exec == nil {
+		return
+	}
+	env.exec.depth--
+}
+
+//checkContext stops the execution when its context is canceled or timed out
+func (env *Environment) checkContext() error {
+	if env == nil || env.exec == nil {
+		return nil
+	}
+	return env.exec.ctx.Err()
+}
+
+//SetClock defines the function giving the current unix timestamp returned by now()
+func (env *Environment) SetClock(clock func() int64) {
+	env.clock = clock
 }
 
-func (env *Environment) Get(name string) (interface{}, error) {
-	v, exist := env.values[name]
-	if exist {
-		return v, nil
+//SetDeterministic enables the deterministic mode in which the executions only depend on
+//the code, its inputs and the context supplied by the host.
+//The current time comes from the clock or the transaction timestamp and the
+//non deterministic built-ins are rejected.
+func (env *Environment) SetDeterministic(deterministic bool) {
+	env.deterministic = deterministic
+}
+
+func (env *Environment) lookupClock() func() int64 {
+	if env.clock != nil {
+		return env.clock
+	}
+	if env.enclosing != nil {
+		return env.enclosing.lookupClock()
 	}
+	return nil
+}
 
+func (env *Environment) lookupDeterministic() bool {
+	if env.deterministic {
+		return true
+	}
 	if env.enclosing != nil {
-		return env.enclosing.Get(name)
+		return env.enclosing.lookupDeterministic()
 	}
+	return false
+}
+
+//SetOutput defines where print writes, the standard output by default
+func (env *Environment) SetOutput(w io.Writer) {
+	env.output = w
+}
+
+func (env *Environment) lookupOutput() io.Writer {
+	if env.output != nil {
+		return env.output
+	}
+	if env.enclosing != nil {
+		return env.enclosing.lookupOutput()
+	}
+	return nil
+}
+
+//print writes a value on the output of the current execution
+func (env *Environment) print(value Value) error {
+	var w io.Writer = os.Stdout
+	if env != nil && env.exec != nil && env.exec.output != nil {
+		w = env.exec.output
+	}
+	_, err := fmt.Fprintln(w, format(value))
+	return err
+}
 
-	return nil, fmt.Errorf("Undefined variable %s", name)
+func (env *Environment) isDeterministic() bool {
+	return env != nil && env.exec != nil && env.exec.deterministic
 }
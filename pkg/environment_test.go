@@ -8,49 +8,58 @@ import (
 
 func TestSetEnvValue(t *testing.T) {
 	e := NewEnvironment(nil)
-	e.set("a", 2)
-	assert.Equal(t, 2, e.values["a"])
+	e.Set("a", Int(2))
+	assert.Equal(t, Int(2), e.values["a"])
 }
 
 func TestSetEnclosingEnvValue(t *testing.T) {
 
 	enc := NewEnvironment(nil)
-	enc.set("a", 2)
+	enc.Set("a", Int(2))
 
 	e := NewEnvironment(enc)
 
 	assert.Nil(t, e.values["a"])
-	assert.Equal(t, 2, e.enclosing.values["a"])
+	assert.Equal(t, Int(2), e.enclosing.values["a"])
 
-	e.set("a", 5)
-	assert.Equal(t, 5, e.enclosing.values["a"])
+	e.Set("a", Int(5))
+	assert.Equal(t, Int(5), e.enclosing.values["a"])
 
-	e.set("b", 10)
-	assert.Equal(t, 10, e.values["b"])
+	e.Set("b", Int(10))
+	assert.Equal(t, Int(10), e.values["b"])
 	assert.Nil(t, e.enclosing.values["b"])
 }
 
 func TestGetEnvValue(t *testing.T) {
 	e := NewEnvironment(nil)
-	e.set("a", 2)
-	val, err := e.get("a")
+	e.Set("a", Int(2))
+	val, err := e.Get("a")
 	assert.Nil(t, err)
-	assert.Equal(t, 2, val)
+	assert.Equal(t, Int(2), val)
 }
 
 func TestGetEnclosingEnvValue(t *testing.T) {
 	enc := NewEnvironment(nil)
-	enc.set("a", 2)
+	enc.Set("a", Int(2))
 
 	e := NewEnvironment(enc)
 
-	val, err := e.get("a")
+	val, err := e.Get("a")
 	assert.Nil(t, err)
-	assert.Equal(t, 2, val)
+	assert.Equal(t, Int(2), val)
 }
 
 func TestGetUndefinedEnvValue(t *testing.T) {
 	e := NewEnvironment(nil)
-	_, err := e.get("a")
+	_, err := e.Get("a")
 	assert.Error(t, err, "Undefined variable a")
 }
+
+func TestLookupEnclosingTransaction(t *testing.T) {
+	enc := NewEnvironment(nil)
+	e := NewEnvironment(enc)
+	assert.Nil(t, e.lookupTransaction())
+
+	enc.SetTransaction(Transaction{Amount: MustParseDecimal("10")})
+	assert.Equal(t, "10", e.lookupTransaction().Amount.String())
+}
@@ -0,0 +1,139 @@
+package uniris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//LexicalError is an invalid piece of code found by the scanner
+type LexicalError struct {
+	Line    int
+	Column  int
+	Start   int
+	End     int
+	Message string
+}
+
+func (e *LexicalError) Error() string {
+	return fmt.Sprintf("Lexical error at line %d, column %d - %s", e.Line, e.Column, e.Message)
+}
+
+//ErrorList gathers all the errors found in a code
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	messages := make([]string, len(l))
+	for i, err := range l {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+//add appends an error unless the same error was already reported at the same position,
+//as the recovery can stop on the token which caused it
+func (l *ErrorList) add(err error) {
+	for _, e := range *l {
+		if e.Error() == err.Error() {
+			return
+		}
+	}
+	*l = append(*l, err)
+}
+
+//Unwrap returns the errors of the list so they can be inspected with errors.As
+func (l ErrorList) Unwrap() []error {
+	return l
+}
+
+//SyntaxError is a grammar mistake found by the parser
+type SyntaxError struct {
+	Line    int
+	Column  int
+	Start   int
+	End     int
+	Message string
+
+	//Expected is the token type the parser was looking for, empty when several ones were allowed
+	Expected TokenType
+
+	//Found is the type of the unexpected token and Lexeme its text
+	Found  TokenType
+	Lexeme string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Found == TokenEndOfFile {
+		return fmt.Sprintf("Parsing error at end of line %d, column %d - %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("Parsing error at %s of line %d, column %d - %s", e.Lexeme, e.Line, e.Column, e.Message)
+}
+
+//RuntimeError is an error raised by a script while it is executed
+type RuntimeError struct {
+	Line     int
+	Column   int
+	Start    int
+	End      int
+	Operator string
+	Message  string
+	err      error
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("Runtime error at %s of line %d, column %d - %s", e.Operator, e.Line, e.Column, e.Message)
+}
+
+//Unwrap returns the underlying error
+func (e *RuntimeError) Unwrap() error {
+	return e.err
+}
+
+//runtimeError positions an error on the token of the failing operation.
+//Errors already positioned deeper in the script, out of gas and context errors are kept as is.
+func runtimeError(tok token, err error) error {
+	var rerr *RuntimeError
+	var gasErr *OutOfGasError
+	if errors.As(err, &rerr) || errors.As(err, &gasErr) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return &RuntimeError{
+		Line:     tok.Line,
+		Column:   tok.Column,
+		Start:    tok.Start,
+		End:      tok.End,
+		Operator: tok.Lexeme,
+		Message:  err.Error(),
+		err:      err,
+	}
+}
+
+//typeName describes the kind of a value in the error messages, integers and floats are both numbers
+func typeName(v Value) string {
+	switch k := KindOf(v); k {
+	case KindInt, KindFloat:
+		return "number"
+	default:
+		return k.String()
+	}
+}
+
+//operandError explains why an operator cannot be applied to its operands
+func operandError(op token, left Value, right Value) error {
+	l, r := typeName(left), typeName(right)
+	switch op.Type {
+	case TokenPlus:
+		return fmt.Errorf("Cannot add %s to %s", r, l)
+	case TokenMinus:
+		return fmt.Errorf("Cannot subtract %s from %s", r, l)
+	case TokenStar:
+		return fmt.Errorf("Cannot multiply %s by %s", l, r)
+	case TokenSlash:
+		return fmt.Errorf("Cannot divide %s by %s", l, r)
+	case TokenPercent:
+		return fmt.Errorf("Cannot compute the remainder of %s by %s", l, r)
+	default:
+		return fmt.Errorf("Cannot compare %s with %s", l, r)
+	}
+}
@@ -0,0 +1,63 @@
+package uniris
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeError(t *testing.T) {
+	_, err := Interpret("a = 1\nb = a - \"x\"", nil)
+	assert.EqualError(t, err, "Runtime error at - of line 2, column 7 - Cannot subtract string from number")
+
+	var rerr *RuntimeError
+	assert.True(t, errors.As(err, &rerr))
+	assert.Equal(t, 2, rerr.Line)
+	assert.Equal(t, 7, rerr.Column)
+	assert.Equal(t, "-", rerr.Operator)
+	assert.Equal(t, "Cannot subtract string from number", rerr.Message)
+}
+
+func TestRuntimeErrorIsNotParseError(t *testing.T) {
+	_, err := Interpret("a = ", nil)
+	assert.Error(t, err)
+
+	var rerr *RuntimeError
+	assert.False(t, errors.As(err, &rerr))
+}
+
+func TestRuntimeErrorInsideFunction(t *testing.T) {
+	_, err := Interpret(`
+function f(x) {
+    return -x
+}
+f("a")
+`, nil)
+	assert.EqualError(t, err, "Runtime error at - of line 3, column 12 - Cannot negate string")
+}
+
+func TestRuntimeErrorKeepsOutOfGas(t *testing.T) {
+	env := NewEnvironment(nil)
+	env.SetGasLimit(10)
+	_, err := Interpret("while true {}", env)
+
+	var gasErr *OutOfGasError
+	assert.True(t, errors.As(err, &gasErr))
+	var rerr *RuntimeError
+	assert.False(t, errors.As(err, &rerr))
+}
+
+func TestOperandError(t *testing.T) {
+	assert.EqualError(t, operandError(token{Type: TokenPlus}, Bool(true), Int(1)), "Cannot add number to boolean")
+	assert.EqualError(t, operandError(token{Type: TokenStar}, newList(), nil), "Cannot multiply list by nil")
+	assert.EqualError(t, operandError(token{Type: TokenLess}, String("a"), Int(1)), "Cannot compare string with number")
+}
+
+func TestRuntimeErrorSpan(t *testing.T) {
+	_, err := Interpret("s = \"é\"\ns - 1", nil)
+	var rerr *RuntimeError
+	assert.True(t, errors.As(err, &rerr))
+	assert.Equal(t, 11, rerr.Start)
+	assert.Equal(t, 12, rerr.End)
+}
@@ -0,0 +1,91 @@
+package uniris
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+//execution holds the state of a single interpretation run
+type execution struct {
+	ctx           context.Context
+	transfers     *pendingTransfers
+	state         *pendingState
+	gas           *gasMeter
+	depth         int
+	clock         func() int64
+	deterministic bool
+	output        io.Writer
+}
+
+func newExecution(ctx context.Context, env *Environment) *execution {
+	exec := &execution{
+		ctx: ctx,
+		gas: &gasMeter{
+			limit: env.lookupGasLimit(),
+		},
+	}
+	exec.deterministic = env.lookupDeterministic()
+	exec.output = env.lookupOutput()
+	exec.clock = env.lookupClock()
+	if exec.clock == nil && exec.deterministic {
+		if tx := env.lookupTransaction(); tx != nil {
+			timestamp := tx.Timestamp
+			exec.clock = func() int64 {
+				return timestamp
+			}
+		}
+	}
+	if ledger, account := env.lookupLedger(); ledger != nil {
+		exec.transfers = &pendingTransfers{
+			ledger:  ledger,
+			account: account,
+		}
+	}
+	if store, id := env.lookupStateStore(); store != nil {
+		exec.state = &pendingState{
+			store: store,
+			id:    id,
+		}
+	}
+	return exec
+}
+
+//commit applies the side effects of a successful run.
+//The state is encoded and written first, then restored if the transfers are refused by the ledger.
+func (exec *execution) commit() ([]Transfer, error) {
+	restore := func() error { return nil }
+	if exec.state != nil {
+		var err error
+		if restore, err = exec.state.commit(); err != nil {
+			return nil, err
+		}
+	}
+	if exec.transfers == nil {
+		return nil, nil
+	}
+	transfers, err := exec.transfers.commit()
+	if err != nil {
+		if restoreErr := restore(); restoreErr != nil {
+			return nil, fmt.Errorf("%s, the contract state could not be restored: %s", err, restoreErr)
+		}
+		return nil, err
+	}
+	return transfers, nil
+}
+
+//storedField returns the value of a contract state variable kept by the state store and whether it exists
+func (exec *execution) storedField(contract string, field string) (Value, bool, error) {
+	if exec == nil || exec.state == nil {
+		return nil, false, nil
+	}
+	return exec.state.field(contract, field)
+}
+
+//track records a contract whose state is written back to the state store when the run succeeds
+func (exec *execution) track(c *Contract, load bool) error {
+	if exec == nil || exec.state == nil {
+		return nil
+	}
+	return exec.state.track(c, load)
+}
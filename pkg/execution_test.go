@@ -0,0 +1,127 @@
+package uniris
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpretContextTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := InterpretContext(ctx, "while true {}", nil)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestInterpretContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := InterpretContext(ctx, `
+function f() {
+    return 1
+}
+f()
+`, nil)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestInterpretContextRecursion(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := InterpretContext(ctx, `
+function f(n) {
+    if n > 0 {
+        f(n - 1)
+        f(n - 1)
+    }
+}
+f(64)
+`, nil)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestContractCallContext(t *testing.T) {
+	res, err := Interpret(`
+contract Spinner {
+    function spin() {
+        while true {}
+    }
+}
+`, nil)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = res.Contract.CallContext(ctx, "spin")
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestInterpretClock(t *testing.T) {
+	env := NewEnvironment(nil)
+	env.SetClock(func() int64 { return 1000 })
+	res, err := Interpret("now()", env)
+	assert.Nil(t, err)
+	assert.Equal(t, "1000\n", res.Output)
+}
+
+func TestDeterministicNowRequiresClock(t *testing.T) {
+	env := NewEnvironment(nil)
+	env.SetDeterministic(true)
+	_, err := Interpret("now()", env)
+	assert.EqualError(t, err, "Runtime error at now of line 1, column 1 - now requires a clock or a transaction timestamp in deterministic mode")
+}
+
+func TestDeterministicTransactionTimestamp(t *testing.T) {
+	env := NewEnvironment(nil)
+	env.SetDeterministic(true)
+	res, err := Interpret(`
+contract Registry {
+    registeredAt = 0
+    function register() {
+        registeredAt = now()
+        return registeredAt
+    }
+}
+`, env)
+	assert.Nil(t, err)
+
+	env.SetTransaction(Transaction{Timestamp: 1550000000})
+	val, err := res.Contract.Call("register")
+	assert.Nil(t, err)
+	assert.Equal(t, Int(1550000000), val)
+}
+
+func TestDeterministicRejectsNondeterministicBuiltins(t *testing.T) {
+	env := NewEnvironment(nil)
+	env.SetDeterministic(true)
+	_, err := Interpret(`ecies_encrypt("hello", "04")`, env)
+	assert.EqualError(t, err, "Runtime error at ecies_encrypt of line 1, column 1 - ecies_encrypt cannot be called in deterministic mode")
+}
+
+func TestDeterministicRuns(t *testing.T) {
+	code := `
+ledger = {}
+for name in ["carol", "alice", "bob"] {
+    ledger[name] = sha256(name + now())
+}
+keys(ledger)
+ledger
+`
+	outputs := make([]string, 0)
+	for i := 0; i < 2; i++ {
+		env := NewEnvironment(nil)
+		env.SetDeterministic(true)
+		env.SetClock(func() int64 { return 1550000000 })
+		res, err := Interpret(code, env)
+		assert.Nil(t, err)
+		outputs = append(outputs, res.Output)
+	}
+	assert.Equal(t, outputs[0], outputs[1])
+}
@@ -3,46 +3,68 @@ package uniris
 import (
 	"errors"
 	"fmt"
-	"reflect"
+	"math"
 )
 
 type expression interface {
-	evaluate(*Environment) (interface{}, error)
+	evaluate(*Environment) (Value, error)
+	span() Span
 }
 
 //Variable assignation
 type assignExpression struct {
+	Span
 	op  token
 	exp expression
 }
 
-func (e assignExpression) evaluate(env *Environment) (interface{}, error) {
+func (e assignExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasAssign); err != nil {
+		return nil, err
+	}
+
 	value, err := e.exp.evaluate(env)
 	if err != nil {
 		return nil, err
 	}
 
-	env.Set(e.op.Lexeme, value)
+	if err := env.assign(e.op.Lexeme, value); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
 //Variable execution
 type variableExpression struct {
+	Span
 	op token
 }
 
-func (e variableExpression) evaluate(env *Environment) (interface{}, error) {
-	return env.Get(e.op.Lexeme)
+func (e variableExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasVariable); err != nil {
+		return nil, err
+	}
+
+	val, err := env.lookup(e.op.Lexeme)
+	if err != nil {
+		return nil, runtimeError(e.op, err)
+	}
+	return val, nil
 }
 
 //Arithmetic (+ - * /) and logic (== !=  > < >= <=)
 type binaryExpression struct {
+	Span
 	left  expression
 	right expression
 	op    token
 }
 
-func (e binaryExpression) evaluate(env *Environment) (interface{}, error) {
+func (e binaryExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasBinary); err != nil {
+		return nil, err
+	}
+
 	left, err := e.left.evaluate(env)
 	if err != nil {
 		return nil, err
@@ -52,82 +74,150 @@ func (e binaryExpression) evaluate(env *Environment) (interface{}, error) {
 		return nil, err
 	}
 
+	val, err := e.apply(left, right)
+	if err != nil {
+		return nil, runtimeError(e.op, err)
+	}
+	//The lists and the strings built by + cost their size
+	if err := env.useItemsGas(itemCount(val)); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func (e binaryExpression) apply(left Value, right Value) (Value, error) {
+	if isDecimalOperation(e.op, left, right) {
+		return decimalOperation(e.op, left, right)
+	}
+
 	switch e.op.Type {
-	case TokenMinus:
-		return left.(float64) - right.(float64), nil
-	case TokenSlash:
-		return left.(float64) / right.(float64), nil
-	case TokenStar:
-		return left.(float64) * right.(float64), nil
 	case TokenPlus:
-		if reflect.TypeOf(left).String() == "float64" && reflect.TypeOf(right).String() == "float64" {
-			return left.(float64) + right.(float64), nil
+		switch {
+		case KindOf(left) == KindList && KindOf(right) == KindList:
+			l, r := left.(*list), right.(*list)
+			elements := make([]Value, 0, len(l.elements)+len(r.elements))
+			elements = append(elements, l.elements...)
+			return newList(append(elements, r.elements...)...), nil
+		case isNumber(left) && isNumber(right):
+			return numberOperation(e.op, left, right)
+		case KindOf(left) == KindString || KindOf(right) == KindString:
+			return String(format(left) + format(right)), nil
 		}
-		return fmt.Sprintf("%v%v", left, right), nil
-	case TokenGreater:
-		return left.(float64) > right.(float64), nil
-	case TokenGreaterEqual:
-		return left.(float64) >= right.(float64), nil
-	case TokenLess:
-		return left.(float64) < right.(float64), nil
-	case TokenLessEqual:
-		return left.(float64) <= right.(float64), nil
+		return nil, operandError(e.op, left, right)
+	case TokenMinus, TokenSlash, TokenStar, TokenPercent, TokenGreater, TokenGreaterEqual, TokenLess, TokenLessEqual:
+		return numberOperation(e.op, left, right)
 	case TokenEqualEqual:
-		return left == right, nil
+		return Bool(Equal(left, right)), nil
 	case TokenBangEqual:
-		return left != right, nil
+		return Bool(!Equal(left, right)), nil
 	default:
 		return nil, errors.New("Not supported as binary expression")
 	}
 }
 
+//isDecimalOperation reports whether an arithmetic or comparison involves a decimal
+func isDecimalOperation(op token, left Value, right Value) bool {
+	l, r := KindOf(left), KindOf(right)
+	if l != KindDecimal && r != KindDecimal {
+		return false
+	}
+	switch op.Type {
+	case TokenEqualEqual, TokenBangEqual:
+		return false
+	case TokenPlus:
+		return l != KindString && r != KindString
+	default:
+		return true
+	}
+}
+
 //Parenthesis and brackets
 type groupingExpression struct {
+	Span
 	exp expression
 }
 
-func (e groupingExpression) evaluate(env *Environment) (interface{}, error) {
+func (e groupingExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasGrouping); err != nil {
+		return nil, err
+	}
+
 	return e.exp.evaluate(env)
 }
 
 //Not expression or negative one
 type unaryExpression struct {
+	Span
 	op    token
 	right expression
 }
 
-func (e unaryExpression) evaluate(env *Environment) (interface{}, error) {
+func (e unaryExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasUnary); err != nil {
+		return nil, err
+	}
+
 	right, err := e.right.evaluate(env)
 	if err != nil {
 		return nil, err
 	}
 	switch e.op.Type {
 	case TokenBang:
-		return !isTruthy(right), nil
+		return Bool(!isTruthy(right)), nil
 	case TokenMinus:
-		return -right.(float64), nil
+		val, err := negate(right)
+		if err != nil {
+			return nil, runtimeError(e.op, err)
+		}
+		return val, nil
 	}
 
 	return nil, nil
 }
 
+func negate(v Value) (Value, error) {
+	switch n := v.(type) {
+	case Decimal:
+		return n.Neg(), nil
+	case Int:
+		if n == math.MinInt64 {
+			return nil, fmt.Errorf("Integer overflow: -(%d)", n)
+		}
+		return -n, nil
+	case Float:
+		return -n, nil
+	default:
+		return nil, fmt.Errorf("Cannot negate %s", typeName(v))
+	}
+}
+
 //Number, string, booleans
 type literalExpression struct {
-	value interface{}
+	Span
+	value Value
 }
 
-func (e literalExpression) evaluate(env *Environment) (interface{}, error) {
+func (e literalExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasLiteral); err != nil {
+		return nil, err
+	}
+
 	return e.value, nil
 }
 
 //And, OR
 type logicalExpression struct {
+	Span
 	left  expression
 	op    token
 	right expression
 }
 
-func (e logicalExpression) evaluate(env *Environment) (interface{}, error) {
+func (e logicalExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasLogical); err != nil {
+		return nil, err
+	}
+
 	left, err := e.left.evaluate(env)
 	if err != nil {
 		return nil, err
@@ -145,19 +235,33 @@ func (e logicalExpression) evaluate(env *Environment) (interface{}, error) {
 }
 
 type callExpression struct {
+	Span
 	callee expression
 	paren  token
 	args   []expression
 }
 
-func (e callExpression) evaluate(env *Environment) (interface{}, error) {
+func (e callExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasCall); err != nil {
+		return nil, err
+	}
+
 	callee, err := e.callee.evaluate(env)
 	if err != nil {
 		return nil, err
 	}
+	tok := e.paren
+	name := "Function"
+	if v, ok := e.callee.(variableExpression); ok {
+		tok = v.op
+		name = v.op.Lexeme
+	}
+	if isNondeterministic(callee) && env.isDeterministic() {
+		return nil, runtimeError(tok, fmt.Errorf("%s cannot be called in deterministic mode", name))
+	}
 	switch callee.(type) {
 	case callable:
-		args := make([]interface{}, 0)
+		args := make([]Value, 0)
 		for _, arg := range e.args {
 			val, err := arg.evaluate(env)
 			if err != nil {
@@ -166,8 +270,246 @@ func (e callExpression) evaluate(env *Environment) (interface{}, error) {
 			args = append(args, val)
 		}
 		f := callee.(callable)
-		return f.call(env, args...)
+		val, err := f.call(env, args...)
+		if err != nil {
+			return nil, runtimeError(tok, err)
+		}
+		return val, nil
+	default:
+		return nil, runtimeError(tok, fmt.Errorf("Can only call functions, got %s", typeName(callee)))
+	}
+}
+
+//List literal
+type listExpression struct {
+	Span
+	elements []expression
+}
+
+func (e listExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasList); err != nil {
+		return nil, err
+	}
+
+	elements := make([]Value, 0, len(e.elements))
+	for _, el := range e.elements {
+		val, err := el.evaluate(env)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, val)
+	}
+	return newList(elements...), nil
+}
+
+//Indexed access: xs[i]
+type indexExpression struct {
+	Span
+	object  expression
+	bracket token
+	index   expression
+}
+
+func (e indexExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasIndex); err != nil {
+		return nil, err
+	}
+
+	obj, err := e.object.evaluate(env)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := e.index.evaluate(env)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := index(obj, idx)
+	if err != nil {
+		return nil, runtimeError(e.bracket, err)
+	}
+	return val, nil
+}
+
+func index(obj Value, idx Value) (Value, error) {
+	switch o := obj.(type) {
+	case *list:
+		return o.get(idx)
+	case *object:
+		return o.get(idx)
+	case String:
+		chars := []rune(string(o))
+		i, err := toIndex(idx)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= len(chars) {
+			return nil, fmt.Errorf("Index %d out of range [0:%d]", i, len(chars))
+		}
+		return String(chars[i]), nil
+	default:
+		return nil, fmt.Errorf("Can only index lists, objects and strings, got %s", typeName(obj))
+	}
+}
+
+//Indexed assignation: xs[i] = value
+type indexAssignExpression struct {
+	Span
+	object  expression
+	bracket token
+	index   expression
+	value   expression
+}
+
+func (e indexAssignExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasIndexAssign); err != nil {
+		return nil, err
+	}
+
+	obj, err := e.object.evaluate(env)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := e.index.evaluate(env)
+	if err != nil {
+		return nil, err
+	}
+	value, err := e.value.evaluate(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setIndex(obj, idx, value); err != nil {
+		return nil, runtimeError(e.bracket, err)
+	}
+	return nil, nil
+}
+
+func setIndex(obj Value, idx Value, value Value) error {
+	switch o := obj.(type) {
+	case *list:
+		return o.set(idx, value)
+	case *object:
+		return o.set(idx, value)
+	default:
+		return fmt.Errorf("Can only assign to list elements and object keys, got %s", typeName(obj))
+	}
+}
+
+//Object literal: {key: value}
+type objectExpression struct {
+	Span
+	keys   []string
+	values []expression
+}
+
+func (e objectExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasObject); err != nil {
+		return nil, err
+	}
+
+	obj := newObject()
+	for i, k := range e.keys {
+		val, err := e.values[i].evaluate(env)
+		if err != nil {
+			return nil, err
+		}
+		obj.set(String(k), val)
+	}
+	return obj, nil
+}
+
+//Member access: obj.field
+type getExpression struct {
+	Span
+	object expression
+	name   token
+}
+
+func (e getExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasGet); err != nil {
+		return nil, err
+	}
+
+	obj, err := e.object.evaluate(env)
+	if err != nil {
+		return nil, err
+	}
+	val, err := property(obj, e.name.Lexeme)
+	if err != nil {
+		return nil, runtimeError(e.name, err)
+	}
+	return val, nil
+}
+
+func property(obj Value, name string) (Value, error) {
+	switch o := obj.(type) {
+	case *object:
+		return o.get(String(name))
+	case *Contract:
+		return o.get(name)
 	default:
-		return nil, errors.New("Can only call functions")
+		return nil, fmt.Errorf("Only objects have properties, cannot get %s", name)
+	}
+}
+
+//Member assignation: obj.field = value
+type setExpression struct {
+	Span
+	object expression
+	name   token
+	value  expression
+}
+
+func (e setExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasSet); err != nil {
+		return nil, err
+	}
+
+	obj, err := e.object.evaluate(env)
+	if err != nil {
+		return nil, err
+	}
+	o, err := propertyTarget(obj, e.name.Lexeme)
+	if err != nil {
+		return nil, runtimeError(e.name, err)
+	}
+	value, err := e.value.evaluate(env)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.set(String(e.name.Lexeme), value); err != nil {
+		return nil, runtimeError(e.name, err)
+	}
+	return nil, nil
+}
+
+//propertyTarget returns the object whose property can be set
+func propertyTarget(obj Value, name string) (*object, error) {
+	if _, ok := obj.(*Contract); ok {
+		return nil, fmt.Errorf("Contract state can only be changed by its functions, cannot set %s", name)
+	}
+	o, ok := obj.(*object)
+	if !ok {
+		return nil, fmt.Errorf("Only objects have properties, cannot set %s", name)
+	}
+	return o, nil
+}
+
+//Transaction triggering the contract execution
+type transactionExpression struct {
+	Span
+	keyword token
+}
+
+func (e transactionExpression) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasTransaction); err != nil {
+		return nil, err
+	}
+
+	tx := env.lookupTransaction()
+	if tx == nil {
+		return nil, runtimeError(e.keyword, errors.New("No transaction in the execution context"))
 	}
+	return tx.object(), nil
 }
@@ -8,17 +8,17 @@ import (
 
 func TestLiteralExpression(t *testing.T) {
 	e := literalExpression{
-		value: 10,
+		value: Int(10),
 	}
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, 10, val)
+	assert.Equal(t, Int(10), val)
 }
 
 func TestAssignExpression(t *testing.T) {
 	e := assignExpression{
 		exp: literalExpression{
-			value: 10,
+			value: Int(10),
 		},
 		op: token{
 			Lexeme: "a",
@@ -28,15 +28,15 @@ func TestAssignExpression(t *testing.T) {
 	env := NewEnvironment(nil)
 	_, err := e.evaluate(env)
 	assert.Nil(t, err)
-	val, err := env.get("a")
+	val, err := env.Get("a")
 	assert.Nil(t, err)
-	assert.Equal(t, 10, val)
+	assert.Equal(t, Int(10), val)
 }
 
 func TestVariableExpression(t *testing.T) {
 
 	env := NewEnvironment(nil)
-	env.set("a", 10)
+	env.Set("a", Int(10))
 
 	e := variableExpression{
 		op: token{
@@ -46,192 +46,209 @@ func TestVariableExpression(t *testing.T) {
 
 	val, err := e.evaluate(env)
 	assert.Nil(t, err)
-	assert.Equal(t, 10, val)
+	assert.Equal(t, Int(10), val)
 }
 
 func TestBinaryMinusExpression(t *testing.T) {
 	e := binaryExpression{
 		left: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		right: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		op: token{Type: TokenMinus},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, float64(0), val)
+	assert.Equal(t, Float(0), val)
 }
 
 func TestBinaryPlusExpression(t *testing.T) {
 	e := binaryExpression{
 		left: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		right: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		op: token{Type: TokenPlus},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, float64(20), val)
+	assert.Equal(t, Float(20), val)
 
 	e = binaryExpression{
 		left: literalExpression{
-			value: "hello ",
+			value: String("hello "),
 		},
 		right: literalExpression{
-			value: "world",
+			value: String("world"),
 		},
 		op: token{Type: TokenPlus},
 	}
 	val, err = e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, "hello world", val)
+	assert.Equal(t, String("hello world"), val)
+}
+
+func TestBinaryPlusOperandTypes(t *testing.T) {
+	res, err := Interpret("[1] + \"b\"\nfunction none() {}\n\"a\" + 1\n1 + \"a\"\n\"x\" + none()", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "[1]b\na1\n1a\nx<nil>\n", res.Output)
+
+	for code, message := range map[string]string{
+		"1 + true":                          "Runtime error at + of line 1, column 3 - Cannot add boolean to number",
+		"function none() {}\ntrue + none()": "Runtime error at + of line 2, column 6 - Cannot add nil to boolean",
+		"[1] + {}":                          "Runtime error at + of line 1, column 5 - Cannot add object to list",
+		"1.5d + false":                      "Runtime error at + of line 1, column 6 - Cannot add boolean to decimal",
+	} {
+		_, err, _, vmErr := runBoth(t, code)
+		assert.EqualError(t, err, message)
+		assert.Equal(t, err, vmErr)
+	}
 }
 
 func TestBinaryStarExpression(t *testing.T) {
 	e := binaryExpression{
 		left: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		right: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		op: token{Type: TokenStar},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, float64(100), val)
+	assert.Equal(t, Float(100), val)
 }
 
 func TestBinarySlashExpression(t *testing.T) {
 	e := binaryExpression{
 		left: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		right: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		op: token{Type: TokenSlash},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, float64(1), val)
+	assert.Equal(t, Float(1), val)
 }
 
 func TestBinaryGreaterExpression(t *testing.T) {
 	e := binaryExpression{
 		left: literalExpression{
-			value: float64(11),
+			value: Float(11),
 		},
 		right: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		op: token{Type: TokenGreater},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, true, val)
+	assert.Equal(t, Bool(true), val)
 }
 
 func TestBinaryGreaterEqualExpression(t *testing.T) {
 	e := binaryExpression{
 		left: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		right: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		op: token{Type: TokenGreaterEqual},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, true, val)
+	assert.Equal(t, Bool(true), val)
 }
 
 func TestBinaryLessExpression(t *testing.T) {
 	e := binaryExpression{
 		left: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		right: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		op: token{Type: TokenLess},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, false, val)
+	assert.Equal(t, Bool(false), val)
 }
 
 func TestBinaryLessEqualExpression(t *testing.T) {
 	e := binaryExpression{
 		left: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		right: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		op: token{Type: TokenLessEqual},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, true, val)
+	assert.Equal(t, Bool(true), val)
 }
 
 func TestBinaryEqualEqualExpression(t *testing.T) {
 	e := binaryExpression{
 		left: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		right: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		op: token{Type: TokenEqualEqual},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, true, val)
+	assert.Equal(t, Bool(true), val)
 }
 
 func TestBinaryBangEqualExpression(t *testing.T) {
 	e := binaryExpression{
 		left: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		right: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 		op: token{Type: TokenBangEqual},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, false, val)
+	assert.Equal(t, Bool(false), val)
 }
 
 func TestGroupExpression(t *testing.T) {
 	e := groupingExpression{
 		exp: literalExpression{
-			value: 10,
+			value: Int(10),
 		},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, 10, val)
+	assert.Equal(t, Int(10), val)
 }
 
 func TestUnaryBangExpression(t *testing.T) {
@@ -240,13 +257,13 @@ func TestUnaryBangExpression(t *testing.T) {
 			Type: TokenBang,
 		},
 		right: literalExpression{
-			value: true,
+			value: Bool(true),
 		},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, false, val)
+	assert.Equal(t, Bool(false), val)
 
 }
 
@@ -256,22 +273,22 @@ func TestUnaryMinusExpression(t *testing.T) {
 			Type: TokenMinus,
 		},
 		right: literalExpression{
-			value: float64(10),
+			value: Float(10),
 		},
 	}
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, float64(-10), val)
+	assert.Equal(t, Float(-10), val)
 }
 
 func TestLogicalANDExpression(t *testing.T) {
 	e := logicalExpression{
 		left: literalExpression{
-			value: true,
+			value: Bool(true),
 		},
 		right: literalExpression{
-			value: 10,
+			value: Int(10),
 		},
 		op: token{
 			Type: TokenAnd,
@@ -280,14 +297,14 @@ func TestLogicalANDExpression(t *testing.T) {
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, 10, val)
+	assert.Equal(t, Int(10), val)
 
 	e = logicalExpression{
 		left: literalExpression{
-			value: false,
+			value: Bool(false),
 		},
 		right: literalExpression{
-			value: 10,
+			value: Int(10),
 		},
 		op: token{
 			Type: TokenAnd,
@@ -296,16 +313,16 @@ func TestLogicalANDExpression(t *testing.T) {
 
 	val, err = e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, false, val)
+	assert.Equal(t, Bool(false), val)
 }
 
 func TestLogicalORExpression(t *testing.T) {
 	e := logicalExpression{
 		left: literalExpression{
-			value: true,
+			value: Bool(true),
 		},
 		right: literalExpression{
-			value: 10,
+			value: Int(10),
 		},
 		op: token{
 			Type: TokenOr,
@@ -314,14 +331,14 @@ func TestLogicalORExpression(t *testing.T) {
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, true, val)
+	assert.Equal(t, Bool(true), val)
 
 	e = logicalExpression{
 		left: literalExpression{
-			value: false,
+			value: Bool(false),
 		},
 		right: literalExpression{
-			value: 10,
+			value: Int(10),
 		},
 		op: token{
 			Type: TokenOr,
@@ -330,14 +347,14 @@ func TestLogicalORExpression(t *testing.T) {
 
 	val, err = e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, 10, val)
+	assert.Equal(t, Int(10), val)
 }
 
 func TestCallExpression(t *testing.T) {
 	e := callExpression{
 		args: []expression{
 			literalExpression{
-				value: 10,
+				value: Int(10),
 			},
 		},
 		callee: testFuncExpression{},
@@ -345,7 +362,7 @@ func TestCallExpression(t *testing.T) {
 
 	val, err := e.evaluate(NewEnvironment(nil))
 	assert.Nil(t, err)
-	assert.Equal(t, 10, val)
+	assert.Equal(t, Int(10), val)
 }
 
 func TestCallNotCallableExpression(t *testing.T) {
@@ -358,14 +375,161 @@ func TestCallNotCallableExpression(t *testing.T) {
 }
 
 type testFuncExpression struct {
+	Span
 }
 
-func (f testFuncExpression) evaluate(env *Environment) (interface{}, error) {
+func (f testFuncExpression) evaluate(env *Environment) (Value, error) {
 	return testFuncCallable{}, nil
 }
 
-type testFuncCallable struct{}
+type testFuncCallable struct {
+	builtin
+}
 
-func (f testFuncCallable) call(env *Environment, args ...interface{}) (res interface{}, err error) {
+func (f testFuncCallable) call(env *Environment, args ...Value) (res Value, err error) {
 	return args[0], nil
 }
+
+func TestListExpression(t *testing.T) {
+	e := listExpression{
+		elements: []expression{
+			literalExpression{value: Float(1)},
+			literalExpression{value: String("a")},
+		},
+	}
+
+	val, err := e.evaluate(NewEnvironment(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, newList(Float(1), String("a")), val)
+}
+
+func TestBinaryPlusListExpression(t *testing.T) {
+	e := binaryExpression{
+		left:  literalExpression{value: newList(Float(1))},
+		right: literalExpression{value: newList(Float(2))},
+		op:    token{Type: TokenPlus},
+	}
+
+	val, err := e.evaluate(NewEnvironment(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, newList(Float(1), Float(2)), val)
+}
+
+func TestBinaryEqualEqualListExpression(t *testing.T) {
+	e := binaryExpression{
+		left:  literalExpression{value: newList(Float(1), String("a"))},
+		right: literalExpression{value: newList(Float(1), String("a"))},
+		op:    token{Type: TokenEqualEqual},
+	}
+
+	val, err := e.evaluate(NewEnvironment(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(true), val)
+}
+
+func TestIndexExpression(t *testing.T) {
+	env := NewEnvironment(nil)
+	env.Set("xs", newList(Float(1), Float(2)))
+
+	e := indexExpression{
+		object: variableExpression{op: token{Lexeme: "xs"}},
+		index:  literalExpression{value: Float(1)},
+	}
+	val, err := e.evaluate(env)
+	assert.Nil(t, err)
+	assert.Equal(t, Float(2), val)
+
+	e.index = literalExpression{value: Float(2)}
+	_, err = e.evaluate(env)
+	assert.EqualError(t, err, "Runtime error at  of line 0, column 0 - Index 2 out of range [0:2]")
+
+	e = indexExpression{
+		object: literalExpression{value: String("abc")},
+		index:  literalExpression{value: Float(1)},
+	}
+	val, err = e.evaluate(env)
+	assert.Nil(t, err)
+	assert.Equal(t, String("b"), val)
+}
+
+func TestIndexAssignExpression(t *testing.T) {
+	env := NewEnvironment(nil)
+	xs := newList(Float(1), Float(2))
+	env.Set("xs", xs)
+
+	e := indexAssignExpression{
+		object: variableExpression{op: token{Lexeme: "xs"}},
+		index:  literalExpression{value: Float(0)},
+		value:  literalExpression{value: String("a")},
+	}
+	_, err := e.evaluate(env)
+	assert.Nil(t, err)
+	assert.Equal(t, String("a"), xs.elements[0])
+
+	e.index = literalExpression{value: Float(5)}
+	_, err = e.evaluate(env)
+	assert.EqualError(t, err, "Runtime error at  of line 0, column 0 - Index 5 out of range [0:2]")
+}
+
+func TestObjectExpression(t *testing.T) {
+	e := objectExpression{
+		keys:   []string{"a", "b"},
+		values: []expression{literalExpression{value: Float(1)}, literalExpression{value: String("x")}},
+	}
+
+	val, err := e.evaluate(NewEnvironment(nil))
+	assert.Nil(t, err)
+	o := val.(*object)
+	assert.Equal(t, []Value{String("a"), String("b")}, o.keys)
+	assert.Equal(t, String("x"), o.lookup(String("b")))
+}
+
+func TestGetSetExpression(t *testing.T) {
+	env := NewEnvironment(nil)
+	o := newObject()
+	env.Set("o", o)
+
+	set := setExpression{
+		object: variableExpression{op: token{Lexeme: "o"}},
+		name:   token{Lexeme: "a"},
+		value:  literalExpression{value: Float(1)},
+	}
+	_, err := set.evaluate(env)
+	assert.Nil(t, err)
+
+	get := getExpression{
+		object: variableExpression{op: token{Lexeme: "o"}},
+		name:   token{Lexeme: "a"},
+	}
+	val, err := get.evaluate(env)
+	assert.Nil(t, err)
+	assert.Equal(t, Float(1), val)
+
+	get.name = token{Lexeme: "b"}
+	_, err = get.evaluate(env)
+	assert.EqualError(t, err, "Runtime error at b of line 0, column 0 - Undefined key b")
+
+	get.object = literalExpression{value: String("a")}
+	_, err = get.evaluate(env)
+	assert.EqualError(t, err, "Runtime error at b of line 0, column 0 - Only objects have properties, cannot get b")
+}
+
+func TestIndexObjectExpression(t *testing.T) {
+	env := NewEnvironment(nil)
+	o := newObject()
+	env.Set("o", o)
+
+	_, err := indexAssignExpression{
+		object: variableExpression{op: token{Lexeme: "o"}},
+		index:  literalExpression{value: String("a")},
+		value:  literalExpression{value: Bool(true)},
+	}.evaluate(env)
+	assert.Nil(t, err)
+
+	val, err := indexExpression{
+		object: variableExpression{op: token{Lexeme: "o"}},
+		index:  literalExpression{value: String("a")},
+	}.evaluate(env)
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(true), val)
+}
@@ -1,19 +1,47 @@
 package uniris
 
 import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
+
+	"golang.org/x/crypto/blake2b"
 )
 
 type callable interface {
-	call(*Environment, ...interface{}) (interface{}, error)
+	Value
+	call(*Environment, ...Value) (Value, error)
 }
 
 type function struct {
-	declaration funcStatement
+	declaration *funcStatement
+}
+
+func (f function) Kind() Kind {
+	return KindFunction
+}
+
+func (f function) String() string {
+	return fmt.Sprintf("<function %s>", f.declaration.name.Lexeme)
 }
 
-func (f function) call(env *Environment, args ...interface{}) (res interface{}, err error) {
+func (f function) isValue() {}
+
+func (f function) call(env *Environment, args ...Value) (res Value, err error) {
+	if err := env.checkContext(); err != nil {
+		return nil, err
+	}
+	if err := env.enterCall(); err != nil {
+		return nil, err
+	}
+	defer env.leaveCall()
 	newEnvironment := NewEnvironment(env)
 
 	if len(args) != len(f.declaration.params) {
@@ -21,12 +49,16 @@ func (f function) call(env *Environment, args ...interface{}) (res interface{},
 	}
 
 	for i := 0; i < len(f.declaration.params); i++ {
-		newEnvironment.Set(f.declaration.params[i].Lexeme, args[i])
+		newEnvironment.define(f.declaration.params[i].Lexeme, args[i])
 	}
 
 	defer func() {
 		if x := recover(); x != nil {
-			res = x
+			v, ok := x.(Value)
+			if !ok {
+				panic(x)
+			}
+			res = v
 		}
 	}()
 	res, err = f.declaration.body.evaluate(newEnvironment)
@@ -37,8 +69,576 @@ func (f function) call(env *Environment, args ...interface{}) (res interface{},
 }
 
 //GLOBAL FUNCTIONS (BUILT-IN)
-type currentTimestampFunc struct{}
+var builtins = map[string]callable{
+	"now":      currentTimestampFunc{},
+	"len":      lenFunc{},
+	"push":     pushFunc{},
+	"pop":      popFunc{},
+	"slice":    sliceFunc{},
+	"contains": containsFunc{},
+	"keys":     keysFunc{},
+	"values":   valuesFunc{},
+	"has":      hasFunc{},
+	"div":      divFunc{},
+	"send":     sendFunc{},
+	"decimal":  decimalFunc{},
+	"hash":     hashFunc{},
+	"sha256":   hashAlgorithmFunc{algorithm: "sha256"},
+	"sha3_256": hashAlgorithmFunc{algorithm: "sha3-256"},
+	"sha512":   hashAlgorithmFunc{algorithm: "sha512"},
+	"blake2b":  hashAlgorithmFunc{algorithm: "blake2b"},
+
+	"verify":         verifyFunc{},
+	"verify_ed25519": verifyCurveFunc{curve: "ed25519"},
+	"verify_p256":    verifyCurveFunc{curve: "p256"},
+
+	"encrypt":       encryptFunc{},
+	"decrypt":       decryptFunc{},
+	"ecies_encrypt": eciesEncryptFunc{},
+	"ecies_decrypt": eciesDecryptFunc{},
+}
+
+//nondeterministicFunc is implemented by the built-ins whose result is not derived from their arguments only.
+//They are rejected in deterministic mode.
+type nondeterministicFunc interface {
+	nondeterministic()
+}
+
+//isNondeterministic reports whether a callee is a nondeterministic built-in or a native function declared as such
+func isNondeterministic(callee Value) bool {
+	switch f := callee.(type) {
+	case nondeterministicFunc:
+		return true
+	case nativeFunc:
+		return f.fn.Signature().Nondeterministic
+	default:
+		return false
+	}
+}
+
+func checkArity(name string, args []Value, arity int) error {
+	if len(args) != arity {
+		return fmt.Errorf("%s expects %d arguments, got %d", name, arity, len(args))
+	}
+	return nil
+}
+
+type currentTimestampFunc struct {
+	builtin
+}
+
+func (f currentTimestampFunc) call(env *Environment, args ...Value) (Value, error) {
+	if env != nil && env.exec != nil {
+		if env.exec.clock != nil {
+			return Int(env.exec.clock()), nil
+		}
+		if env.exec.deterministic {
+			return nil, errors.New("now requires a clock or a transaction timestamp in deterministic mode")
+		}
+	}
+	return Int(time.Now().Unix()), nil
+}
+
+var hashAlgorithms = map[string]func([]byte) []byte{
+	"sha256": func(data []byte) []byte {
+		sum := sha256.Sum256(data)
+		return sum[:]
+	},
+	"sha3-256": func(data []byte) []byte {
+		sum := sha3.Sum256(data)
+		return sum[:]
+	},
+	"sha512": func(data []byte) []byte {
+		sum := sha512.Sum512(data)
+		return sum[:]
+	},
+	"blake2b": func(data []byte) []byte {
+		sum := blake2b.Sum512(data)
+		return sum[:]
+	},
+}
+
+//hashFunc hashes data with the algorithm given as second argument: hash(data, "sha256")
+type hashFunc struct {
+	builtin
+}
+
+func (f hashFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("hash", args, 2); err != nil {
+		return nil, err
+	}
+	algorithm, ok := args[1].(String)
+	if !ok {
+		return nil, fmt.Errorf("hash expects an algorithm name, got %v", args[1])
+	}
+	return hashAlgorithmFunc{algorithm: string(algorithm)}.call(env, args[0])
+}
+
+//hashAlgorithmFunc hashes data with a fixed algorithm: sha256(data)
+type hashAlgorithmFunc struct {
+	builtin
+	algorithm string
+}
+
+func (f hashAlgorithmFunc) call(env *Environment, args ...Value) (Value, error) {
+	h, exist := hashAlgorithms[f.algorithm]
+	if !exist {
+		names := make([]string, 0, len(hashAlgorithms))
+		for name := range hashAlgorithms {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("Unsupported hash algorithm %s, expected one of %s", f.algorithm, strings.Join(names, ", "))
+	}
+	if err := checkArity(f.algorithm, args, 1); err != nil {
+		return nil, err
+	}
+	data, ok := args[0].(String)
+	if !ok {
+		return nil, fmt.Errorf("%s expects a string, got %v", f.algorithm, args[0])
+	}
+	if err := env.useItemsGas(len(data)); err != nil {
+		return nil, err
+	}
+	return String(hex.EncodeToString(h([]byte(data)))), nil
+}
+
+type lenFunc struct {
+	builtin
+}
+
+func (f lenFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("len", args, 1); err != nil {
+		return nil, err
+	}
+	switch v := args[0].(type) {
+	case *list:
+		return Int(len(v.elements)), nil
+	case *object:
+		return Int(len(v.keys)), nil
+	case String:
+		return Int(len([]rune(string(v)))), nil
+	default:
+		return nil, fmt.Errorf("len expects a list, an object or a string, got %v", v)
+	}
+}
+
+type pushFunc struct {
+	builtin
+}
+
+func (f pushFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("push", args, 2); err != nil {
+		return nil, err
+	}
+	l, ok := args[0].(*list)
+	if !ok {
+		return nil, fmt.Errorf("push expects a list, got %v", args[0])
+	}
+	if err := env.useItemsGas(1); err != nil {
+		return nil, err
+	}
+	l.elements = append(l.elements, args[1])
+	return nil, nil
+}
+
+type popFunc struct {
+	builtin
+}
+
+func (f popFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("pop", args, 1); err != nil {
+		return nil, err
+	}
+	l, ok := args[0].(*list)
+	if !ok {
+		return nil, fmt.Errorf("pop expects a list, got %v", args[0])
+	}
+	if len(l.elements) == 0 {
+		return nil, errors.New("Cannot pop from an empty list")
+	}
+	last := l.elements[len(l.elements)-1]
+	l.elements = l.elements[:len(l.elements)-1]
+	return last, nil
+}
+
+type sliceFunc struct {
+	builtin
+}
+
+func (f sliceFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("slice", args, 3); err != nil {
+		return nil, err
+	}
+	start, err := toIndex(args[1])
+	if err != nil {
+		return nil, err
+	}
+	end, err := toIndex(args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	var length int
+	switch v := args[0].(type) {
+	case *list:
+		length = len(v.elements)
+	case String:
+		length = len([]rune(string(v)))
+	default:
+		return nil, fmt.Errorf("slice expects a list or a string, got %v", v)
+	}
+	if start < 0 || end > length || start > end {
+		return nil, fmt.Errorf("Slice bounds [%d:%d] out of range [0:%d]", start, end, length)
+	}
+
+	if l, ok := args[0].(*list); ok {
+		if err := env.useItemsGas(end - start); err != nil {
+			return nil, err
+		}
+		elements := make([]Value, end-start)
+		copy(elements, l.elements[start:end])
+		return newList(elements...), nil
+	}
+	//The whole string is decoded to find the characters
+	if err := env.useItemsGas(itemCount(args[0])); err != nil {
+		return nil, err
+	}
+	return String([]rune(string(args[0].(String)))[start:end]), nil
+}
+
+type containsFunc struct {
+	builtin
+}
 
-func (f currentTimestampFunc) call(env *Environment, args ...interface{}) (interface{}, error) {
-	return time.Now().Unix(), nil
+func (f containsFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("contains", args, 2); err != nil {
+		return nil, err
+	}
+	if err := env.useItemsGas(itemCount(args[0])); err != nil {
+		return nil, err
+	}
+	switch v := args[0].(type) {
+	case *list:
+		for _, el := range v.elements {
+			if Equal(el, args[1]) {
+				return Bool(true), nil
+			}
+		}
+		return Bool(false), nil
+	case String:
+		sub, ok := args[1].(String)
+		if !ok {
+			return nil, fmt.Errorf("contains expects a string to search in a string, got %v", args[1])
+		}
+		return Bool(strings.Contains(string(v), string(sub))), nil
+	default:
+		return nil, fmt.Errorf("contains expects a list or a string, got %v", v)
+	}
+}
+
+type keysFunc struct {
+	builtin
+}
+
+func (f keysFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("keys", args, 1); err != nil {
+		return nil, err
+	}
+	o, ok := args[0].(*object)
+	if !ok {
+		return nil, fmt.Errorf("keys expects an object, got %v", args[0])
+	}
+	if err := env.useItemsGas(len(o.keys)); err != nil {
+		return nil, err
+	}
+	keys := make([]Value, len(o.keys))
+	copy(keys, o.keys)
+	return newList(keys...), nil
+}
+
+type valuesFunc struct {
+	builtin
+}
+
+func (f valuesFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("values", args, 1); err != nil {
+		return nil, err
+	}
+	o, ok := args[0].(*object)
+	if !ok {
+		return nil, fmt.Errorf("values expects an object, got %v", args[0])
+	}
+	if err := env.useItemsGas(len(o.keys)); err != nil {
+		return nil, err
+	}
+	values := make([]Value, len(o.keys))
+	for i, k := range o.keys {
+		values[i] = o.lookup(k)
+	}
+	return newList(values...), nil
+}
+
+type hasFunc struct {
+	builtin
+}
+
+func (f hasFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("has", args, 2); err != nil {
+		return nil, err
+	}
+	o, ok := args[0].(*object)
+	if !ok {
+		return nil, fmt.Errorf("has expects an object, got %v", args[0])
+	}
+	return Bool(o.has(args[1])), nil
+}
+
+type sendFunc struct {
+	builtin
+}
+
+func (f sendFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("send", args, 2); err != nil {
+		return nil, err
+	}
+	to, ok := args[0].(String)
+	if !ok {
+		return nil, fmt.Errorf("send expects an address, got %v", args[0])
+	}
+	amount, err := toAmount(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("send expects an amount, got %v", args[1])
+	}
+	if env == nil || env.exec == nil || env.exec.transfers == nil {
+		return nil, errors.New("No ledger in the execution context")
+	}
+	return nil, env.exec.transfers.send(string(to), amount)
+}
+
+//verifyFunc checks a signature on the curve given as last argument: verify(publicKey, message, signature, "ed25519")
+type verifyFunc struct {
+	builtin
+}
+
+func (f verifyFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("verify", args, 4); err != nil {
+		return nil, err
+	}
+	curve, ok := args[3].(String)
+	if !ok {
+		return nil, fmt.Errorf("verify expects a curve name, got %v", args[3])
+	}
+	return verifyCurveFunc{curve: string(curve)}.call(env, args[:3]...)
+}
+
+//verifyCurveFunc checks a signature on a fixed curve: verify_ed25519(publicKey, message, signature)
+//The public key and the signature are hex encoded, the message is signed as is.
+type verifyCurveFunc struct {
+	builtin
+	curve string
+}
+
+func (f verifyCurveFunc) call(env *Environment, args ...Value) (Value, error) {
+	verify, exist := signatureCurves[f.curve]
+	if !exist {
+		names := make([]string, 0, len(signatureCurves))
+		for name := range signatureCurves {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("Unsupported signature curve %s, expected one of %s", f.curve, strings.Join(names, ", "))
+	}
+	if err := checkArity("verify_"+f.curve, args, 3); err != nil {
+		return nil, err
+	}
+
+	publicKey, err := decodeHexArg("public key", args[0])
+	if err != nil {
+		return nil, err
+	}
+	message, ok := args[1].(String)
+	if !ok {
+		return nil, fmt.Errorf("verify expects a string message, got %v", args[1])
+	}
+	signature, err := decodeHexArg("signature", args[2])
+	if err != nil {
+		return nil, err
+	}
+	valid, err := verify(publicKey, []byte(message), signature)
+	if err != nil {
+		return nil, err
+	}
+	return Bool(valid), nil
+}
+
+func decodeHexArg(name string, arg Value) ([]byte, error) {
+	s, ok := arg.(String)
+	if !ok {
+		return nil, fmt.Errorf("Invalid %s: expected an hex string, got %v", name, arg)
+	}
+	b, err := hex.DecodeString(string(s))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s: %s", name, err)
+	}
+	return b, nil
+}
+
+func secretArg(env *Environment, arg Value) ([]byte, error) {
+	name, ok := arg.(String)
+	if !ok {
+		return nil, fmt.Errorf("Expect a secret name, got %v", arg)
+	}
+	if env == nil {
+		return nil, fmt.Errorf("Undefined secret %s", name)
+	}
+	return env.lookupSecret(string(name))
+}
+
+//encryptFunc encrypts with AES-256-GCM: encrypt(plaintext, secretName, nonce)
+type encryptFunc struct {
+	builtin
+}
+
+func (f encryptFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("encrypt", args, 3); err != nil {
+		return nil, err
+	}
+	plaintext, ok := args[0].(String)
+	if !ok {
+		return nil, fmt.Errorf("encrypt expects a string, got %v", args[0])
+	}
+	key, err := secretArg(env, args[1])
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := decodeHexArg("nonce", args[2])
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := aesEncrypt(key, nonce, []byte(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	return String(hex.EncodeToString(ciphertext)), nil
+}
+
+//decryptFunc decrypts with AES-256-GCM: decrypt(ciphertext, secretName, nonce)
+type decryptFunc struct {
+	builtin
+}
+
+func (f decryptFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("decrypt", args, 3); err != nil {
+		return nil, err
+	}
+	ciphertext, err := decodeHexArg("ciphertext", args[0])
+	if err != nil {
+		return nil, err
+	}
+	key, err := secretArg(env, args[1])
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := decodeHexArg("nonce", args[2])
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aesDecrypt(key, nonce, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return String(plaintext), nil
+}
+
+//eciesEncryptFunc encrypts for a P-256 public key: ecies_encrypt(plaintext, publicKey)
+type eciesEncryptFunc struct {
+	builtin
+}
+
+//The ephemeral key is random
+func (f eciesEncryptFunc) nondeterministic() {}
+
+func (f eciesEncryptFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("ecies_encrypt", args, 2); err != nil {
+		return nil, err
+	}
+	plaintext, ok := args[0].(String)
+	if !ok {
+		return nil, fmt.Errorf("ecies_encrypt expects a string, got %v", args[0])
+	}
+	publicKey, err := decodeHexArg("public key", args[1])
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := eciesEncrypt(publicKey, []byte(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	return String(hex.EncodeToString(ciphertext)), nil
+}
+
+//eciesDecryptFunc decrypts with a P-256 private key: ecies_decrypt(ciphertext, secretName)
+type eciesDecryptFunc struct {
+	builtin
+}
+
+func (f eciesDecryptFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("ecies_decrypt", args, 2); err != nil {
+		return nil, err
+	}
+	ciphertext, err := decodeHexArg("ciphertext", args[0])
+	if err != nil {
+		return nil, err
+	}
+	key, err := secretArg(env, args[1])
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := eciesDecrypt(key, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return String(plaintext), nil
+}
+
+//divFunc is the integer division, truncated toward zero
+type divFunc struct {
+	builtin
+}
+
+func (f divFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("div", args, 2); err != nil {
+		return nil, err
+	}
+	l, lok := args[0].(Int)
+	r, rok := args[1].(Int)
+	if !lok || !rok {
+		return nil, fmt.Errorf("div expects two integers, got %s and %s", KindOf(args[0]), KindOf(args[1]))
+	}
+	if r == 0 {
+		return nil, errors.New("Division by zero")
+	}
+	if l == math.MinInt64 && r == -1 {
+		return nil, fmt.Errorf("Integer overflow: div(%d, %d)", l, r)
+	}
+	return l / r, nil
+}
+
+type decimalFunc struct {
+	builtin
+}
+
+func (f decimalFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := checkArity("decimal", args, 1); err != nil {
+		return nil, err
+	}
+	switch v := args[0].(type) {
+	case String:
+		return decimalResult(ParseDecimal(string(v)))
+	case Float:
+		return decimalResult(decimalFromFloat(float64(v)))
+	default:
+		return decimalResult(toDecimal(v))
+	}
 }
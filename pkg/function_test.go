@@ -0,0 +1,173 @@
+package uniris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLenFunc(t *testing.T) {
+	val, err := lenFunc{}.call(nil, newList(Float(1), Float(2)))
+	assert.Nil(t, err)
+	assert.Equal(t, Int(2), val)
+
+	val, err = lenFunc{}.call(nil, String("héllo"))
+	assert.Nil(t, err)
+	assert.Equal(t, Int(5), val)
+
+	_, err = lenFunc{}.call(nil, Bool(true))
+	assert.Error(t, err)
+
+	_, err = lenFunc{}.call(nil)
+	assert.EqualError(t, err, "len expects 1 arguments, got 0")
+}
+
+func TestPushPopFunc(t *testing.T) {
+	l := newList()
+	_, err := pushFunc{}.call(nil, l, Float(1))
+	assert.Nil(t, err)
+	_, err = pushFunc{}.call(nil, l, String("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Value{Float(1), String("a")}, l.elements)
+
+	val, err := popFunc{}.call(nil, l)
+	assert.Nil(t, err)
+	assert.Equal(t, String("a"), val)
+	val, err = popFunc{}.call(nil, l)
+	assert.Nil(t, err)
+	assert.Equal(t, Float(1), val)
+
+	_, err = popFunc{}.call(nil, l)
+	assert.EqualError(t, err, "Cannot pop from an empty list")
+
+	_, err = pushFunc{}.call(nil, String("a"), Float(1))
+	assert.Error(t, err)
+}
+
+func TestSliceFunc(t *testing.T) {
+	l := newList(Float(1), Float(2), Float(3))
+	val, err := sliceFunc{}.call(nil, l, Float(1), Float(3))
+	assert.Nil(t, err)
+	assert.Equal(t, newList(Float(2), Float(3)), val)
+
+	val, err = sliceFunc{}.call(nil, String("hello"), Float(1), Float(3))
+	assert.Nil(t, err)
+	assert.Equal(t, String("el"), val)
+
+	_, err = sliceFunc{}.call(nil, l, Float(2), Float(4))
+	assert.EqualError(t, err, "Slice bounds [2:4] out of range [0:3]")
+}
+
+func TestContainsFunc(t *testing.T) {
+	l := newList(Float(1), newList(String("a")))
+	val, err := containsFunc{}.call(nil, l, Float(1))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(true), val)
+
+	val, err = containsFunc{}.call(nil, l, newList(String("a")))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(true), val)
+
+	val, err = containsFunc{}.call(nil, l, Float(2))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(false), val)
+
+	val, err = containsFunc{}.call(nil, String("hello"), String("ell"))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(true), val)
+}
+
+func TestKeysValuesFunc(t *testing.T) {
+	o := newObject()
+	o.set(String("a"), Float(1))
+	o.set(String("b"), String("x"))
+
+	val, err := keysFunc{}.call(nil, o)
+	assert.Nil(t, err)
+	assert.Equal(t, newList(String("a"), String("b")), val)
+
+	val, err = valuesFunc{}.call(nil, o)
+	assert.Nil(t, err)
+	assert.Equal(t, newList(Float(1), String("x")), val)
+
+	val, err = lenFunc{}.call(nil, o)
+	assert.Nil(t, err)
+	assert.Equal(t, Int(2), val)
+
+	_, err = keysFunc{}.call(nil, newList())
+	assert.Error(t, err)
+}
+
+func TestHasFunc(t *testing.T) {
+	o := newObject()
+	o.set(String("a"), Float(1))
+
+	val, err := hasFunc{}.call(nil, o, String("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(true), val)
+
+	val, err = hasFunc{}.call(nil, o, String("b"))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(false), val)
+}
+
+func TestHashAlgorithmFunc(t *testing.T) {
+	vectors := []struct {
+		algorithm string
+		data      string
+		digest    string
+	}{
+		{"sha256", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"sha256", "abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"sha3-256", "", "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a"},
+		{"sha3-256", "abc", "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+		{"sha512", "abc", "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{"blake2b", "", "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce"},
+		{"blake2b", "abc", "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+	}
+
+	for _, v := range vectors {
+		val, err := hashAlgorithmFunc{algorithm: v.algorithm}.call(nil, String(v.data))
+		assert.Nil(t, err)
+		assert.Equal(t, String(v.digest), val, v.algorithm)
+
+		val, err = hashFunc{}.call(nil, String(v.data), String(v.algorithm))
+		assert.Nil(t, err)
+		assert.Equal(t, String(v.digest), val, v.algorithm)
+	}
+}
+
+func TestHashFuncErrors(t *testing.T) {
+	_, err := hashFunc{}.call(nil, String("abc"), String("md5"))
+	assert.EqualError(t, err, "Unsupported hash algorithm md5, expected one of blake2b, sha256, sha3-256, sha512")
+
+	_, err = hashAlgorithmFunc{algorithm: "sha256"}.call(nil, Float(1))
+	assert.EqualError(t, err, "sha256 expects a string, got 1")
+}
+
+func TestFunctionParametersAreLocal(t *testing.T) {
+	res, err := Interpret(`
+function fibonacci(n) {
+    if n <= 1 {
+        return n
+    }
+    return fibonacci(n-2) + fibonacci(n-1)
+}
+fibonacci(10)
+`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "55\n", res.Output)
+}
+
+func TestFunctionParametersShadowVariables(t *testing.T) {
+	res, err := Interpret(`
+n = 1
+function show(n) {
+    return n
+}
+show(5)
+n
+`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "5\n1\n", res.Output)
+}
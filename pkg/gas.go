@@ -0,0 +1,115 @@
+package uniris
+
+import "fmt"
+
+type gasKind int
+
+const (
+	gasLiteral gasKind = iota
+	gasVariable
+	gasAssign
+	gasBinary
+	gasGrouping
+	gasUnary
+	gasLogical
+	gasCall
+	gasList
+	gasIndex
+	gasIndexAssign
+	gasObject
+	gasGet
+	gasSet
+	gasTransaction
+	gasExpressionStmt
+	gasPrint
+	gasBlock
+	gasIf
+	gasWhile
+	gasLoopIteration
+	gasFunction
+	gasContract
+	gasReturn
+)
+
+//gasCosts is the gas consumed by the evaluation of each kind of node
+var gasCosts = [...]uint64{
+	gasLiteral:        1,
+	gasVariable:       1,
+	gasAssign:         2,
+	gasBinary:         2,
+	gasGrouping:       0,
+	gasUnary:          1,
+	gasLogical:        1,
+	gasCall:           10,
+	gasList:           2,
+	gasIndex:          2,
+	gasIndexAssign:    3,
+	gasObject:         2,
+	gasGet:            2,
+	gasSet:            3,
+	gasTransaction:    2,
+	gasExpressionStmt: 0,
+	gasPrint:          5,
+	gasBlock:          1,
+	gasIf:             1,
+	gasWhile:          1,
+	gasLoopIteration:  1,
+	gasFunction:       5,
+	gasContract:       10,
+	gasReturn:         1,
+}
+
+const (
+	//gasPerScope is consumed for each enclosing environment walked through to find a variable
+	gasPerScope = 1
+
+	//gasPerItem is consumed for each list element and each string byte built, copied or read by an operation,
+	//so that the limit also bounds the memory and the time spent on large values
+	gasPerItem = 1
+
+	//maxCallDepth is the number of nested function calls after which an execution fails
+	maxCallDepth = 1024
+)
+
+//itemCount returns the size of a value charged by gasPerItem: the elements of a list or the bytes of a string
+func itemCount(v Value) int {
+	switch x := v.(type) {
+	case *list:
+		return len(x.elements)
+	case String:
+		return len(x)
+	default:
+		return 0
+	}
+}
+
+//OutOfGasError is returned when an execution exceeds its gas limit
+type OutOfGasError struct {
+	Limit uint64
+	Used  uint64
+}
+
+func (e *OutOfGasError) Error() string {
+	return fmt.Sprintf("Out of gas: %d used, limit is %d", e.Used, e.Limit)
+}
+
+//gasMeter counts the gas used by an execution, a zero limit means unlimited
+type gasMeter struct {
+	limit uint64
+	used  uint64
+}
+
+func (m *gasMeter) use(kind gasKind) error {
+	return m.consume(gasCosts[kind])
+}
+
+func (m *gasMeter) consume(amount uint64) error {
+	m.used += amount
+	if m.limit > 0 && m.used > m.limit {
+		return &OutOfGasError{
+			Limit: m.limit,
+			Used:  m.used,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package uniris
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasMeter(t *testing.T) {
+	m := &gasMeter{limit: 3}
+	assert.Nil(t, m.use(gasAssign))
+	assert.Nil(t, m.use(gasLiteral))
+	err := m.use(gasBinary)
+	assert.EqualError(t, err, "Out of gas: 5 used, limit is 3")
+
+	m = &gasMeter{}
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, m.use(gasCall))
+	}
+	assert.Equal(t, uint64(1000), m.used)
+}
+
+func TestInterpretOutOfGas(t *testing.T) {
+	env := NewEnvironment(nil)
+	env.SetGasLimit(1000)
+
+	_, err := Interpret("while true {}", env)
+	var outOfGas *OutOfGasError
+	assert.True(t, errors.As(err, &outOfGas))
+	assert.Equal(t, uint64(1000), outOfGas.Limit)
+	assert.True(t, outOfGas.Used > 1000)
+}
+
+func TestInterpretOutOfGasInFunction(t *testing.T) {
+	env := NewEnvironment(nil)
+	env.SetGasLimit(500)
+
+	_, err := Interpret(`
+function loop(n) {
+    return loop(n + 1)
+}
+loop(0)
+`, env)
+	var outOfGas *OutOfGasError
+	assert.True(t, errors.As(err, &outOfGas))
+}
+
+func TestInterpretGasUsed(t *testing.T) {
+	res, err := Interpret("a = 1 + 2", nil)
+	assert.Nil(t, err)
+	//assign + binary + 2 literals + the globals environment walked through by the assignation
+	assert.Equal(t, uint64(7), res.GasUsed)
+
+	res, err = Interpret("i = 0\nwhile i < 10 { i = i + 1 }", nil)
+	assert.Nil(t, err)
+	more, err := Interpret("i = 0\nwhile i < 20 { i = i + 1 }", nil)
+	assert.Nil(t, err)
+	assert.True(t, more.GasUsed > res.GasUsed)
+}
+
+func TestInterpretOutOfGasMovesNoFunds(t *testing.T) {
+	ledger := NewMemoryLedger(map[string]Decimal{"contract": MustParseDecimal("10")})
+	env := NewEnvironment(nil)
+	env.SetLedger(ledger, "contract")
+	env.SetGasLimit(100)
+
+	_, err := Interpret(`
+send("alice", 1)
+while true {}
+`, env)
+	assert.Error(t, err)
+	balance, _ := ledger.Balance("alice")
+	assert.Equal(t, "0", balance.String())
+}
+
+func TestContractCallGas(t *testing.T) {
+	env := NewEnvironment(nil)
+	res, err := Interpret(`
+contract Counter {
+    count = 0
+    function increment(n) {
+        i = 0
+        while i < n {
+            count = count + 1
+            i = i + 1
+        }
+    }
+}
+`, env)
+	assert.Nil(t, err)
+
+	_, err = res.Contract.Call("increment", Float(10))
+	assert.Nil(t, err)
+	assert.True(t, res.Contract.GasUsed() > 0)
+
+	env.SetGasLimit(200)
+	_, err = res.Contract.Call("increment", Float(1000))
+	var outOfGas *OutOfGasError
+	assert.True(t, errors.As(err, &outOfGas))
+}
+
+func TestGasChargesDataSize(t *testing.T) {
+	gasUsed := func(code string) uint64 {
+		res, err := Interpret(code, nil)
+		assert.Nil(t, err)
+		b, err := Compile(code)
+		assert.Nil(t, err)
+		vmRes, err := b.Run(nil)
+		assert.Nil(t, err)
+		return res.GasUsed + vmRes.GasUsed
+	}
+	long := strings.Repeat("a", 1000)
+
+	//Both engines charge each byte of the strings and each element of the lists built by +
+	assert.Equal(t, uint64(2*2*1000), gasUsed(`"`+long+`" + "`+long+`"`)-gasUsed(`"" + ""`))
+	assert.Equal(t, uint64(2*2), gasUsed("xs = [1, 2]\nys = []\nxs + xs")-gasUsed("xs = [1, 2]\nys = []\nxs + ys"))
+
+	//The hashes charge the bytes hashed
+	assert.Equal(t, uint64(2*1000), gasUsed(`sha256("`+long+`")`)-gasUsed(`sha256("")`))
+
+	//Doubling a list cannot go on for long under a limit
+	env := NewEnvironment(nil)
+	env.SetGasLimit(100000)
+	_, err := Interpret("xs = [1]\nwhile true {\n    xs = xs + xs\n}", env)
+	var outOfGas *OutOfGasError
+	assert.True(t, errors.As(err, &outOfGas))
+}
+
+func TestCallDepthLimit(t *testing.T) {
+	code := "function down(n) {\n    return down(n + 1)\n}\ndown(0)"
+	_, err, _, vmErr := runBoth(t, code)
+	assert.EqualError(t, err, "Runtime error at down of line 2, column 12 - Maximum call depth of 1024 exceeded")
+	assert.Equal(t, err, vmErr)
+
+	//The calls return to the limit
+	res, err := Interpret("function down(n) {\n    if n > 0 {\n        return down(n - 1)\n    }\n    return 0\n}\ndown(1000)\ndown(1000)", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "0\n0\n", res.Output)
+}
+
+func TestGasChargesScopes(t *testing.T) {
+	enc := NewEnvironment(nil)
+	enc.Set("a", Int(1))
+	env := NewEnvironment(NewEnvironment(NewEnvironment(enc)))
+	exec := newExecution(context.Background(), env)
+	env.exec = exec
+
+	val, err := env.lookup("a")
+	assert.Nil(t, err)
+	assert.Equal(t, Int(1), val)
+	assert.Equal(t, uint64(3*gasPerScope), exec.gas.used)
+
+	assert.Nil(t, env.assign("a", Int(2)))
+	assert.Equal(t, uint64(6*gasPerScope), exec.gas.used)
+	assert.Equal(t, Int(2), enc.values["a"])
+
+	_, err = env.lookup("b")
+	assert.EqualError(t, err, "Undefined variable b")
+	assert.Equal(t, uint64(9*gasPerScope), exec.gas.used)
+}
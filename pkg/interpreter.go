@@ -1,39 +1,99 @@
 package uniris
 
-import "fmt"
+import "context"
 
-//Interpret smart contract code
-func Interpret(code string, env *Environment) (string, error) {
+//Result is the outcome of a smart contract interpretation
+type Result struct {
+	//Output contains the values of the top level expressions
+	Output string
 
-	globals := NewEnvironment(nil)
-	globals.Set("now", currentTimestampFunc{})
+	//Contract is the contract declared by the code, nil when the code does not declare any
+	Contract *Contract
 
-	if env == nil {
-		env = NewEnvironment(nil)
-	}
-	env.enclosing = globals
+	//Transfers are the IRIS transfers committed on the ledger
+	Transfers []Transfer
 
-	sc := newScanner(code)
-	tokens := sc.scanTokens()
-	p := parser{
-		tokens: tokens,
-	}
-	stmt, err := p.parse()
+	//GasUsed is the gas consumed by the execution
+	GasUsed uint64
+}
+
+//Interpret smart contract code
+func Interpret(code string, env *Environment) (*Result, error) {
+	return InterpretContext(context.Background(), code, env)
+}
+
+//InterpretContext interprets smart contract code until the context is canceled or times out
+func InterpretContext(ctx context.Context, code string, env *Environment) (*Result, error) {
+	return interpret(ctx, code, env, nil)
+}
+
+func interpret(ctx context.Context, code string, env *Environment, natives map[string]Value) (*Result, error) {
+	stmts, err := parseCode(code)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	return execute(ctx, stmts, env, natives)
+}
 
-	res := ""
+//execute evaluates parsed statements
+func execute(ctx context.Context, stmts []statement, env *Environment, natives map[string]Value) (*Result, error) {
+	env, exec := startExecution(ctx, env, natives)
 
-	for _, s := range stmt {
+	res := &Result{}
+
+	for _, s := range stmts {
 		val, err := s.evaluate(env)
 		if err != nil {
-			return "", err
-		}
-		if val != nil {
-			res += fmt.Sprintf("%v\n", val)
+			return nil, err
 		}
+		res.add(val)
 	}
 
+	if err := res.finish(exec); err != nil {
+		return nil, err
+	}
 	return res, nil
 }
+
+//startExecution links the environment to the built-ins, the host natives and to a new execution
+func startExecution(ctx context.Context, env *Environment, natives map[string]Value) (*Environment, *execution) {
+	globals := NewEnvironment(nil)
+	for name, f := range builtins {
+		globals.Set(name, f)
+	}
+	for name, f := range natives {
+		globals.Set(name, f)
+	}
+
+	if env == nil {
+		env = NewEnvironment(nil)
+	}
+	env.enclosing = globals
+
+	exec := newExecution(ctx, env)
+	globals.exec = exec
+	env.exec = exec
+	return env, exec
+}
+
+//add collects the value of a top level statement
+func (res *Result) add(val Value) {
+	if c, ok := val.(*Contract); ok {
+		res.Contract = c
+		return
+	}
+	if val != nil {
+		res.Output += val.String() + "\n"
+	}
+}
+
+//finish commits the side effects of the execution
+func (res *Result) finish(exec *execution) error {
+	transfers, err := exec.commit()
+	if err != nil {
+		return err
+	}
+	res.Transfers = transfers
+	res.GasUsed = exec.gas.used
+	return nil
+}
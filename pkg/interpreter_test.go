@@ -0,0 +1,36 @@
+package uniris
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpretOutput(t *testing.T) {
+	res, err := Interpret("a = 1\na + 1\nb = [a]", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "2\n", res.Output)
+	assert.Nil(t, res.Contract)
+}
+
+func TestInterpretKeepsEnvironment(t *testing.T) {
+	env := NewEnvironment(nil)
+	_, err := Interpret("a = 1", env)
+	assert.Nil(t, err)
+	res, err := Interpret("a + 1", env)
+	assert.Nil(t, err)
+	assert.Equal(t, "2\n", res.Output)
+}
+
+func TestInterpretLexicalErrors(t *testing.T) {
+	_, err := Interpret("a = 1 # 2\nb = \"x", nil)
+	assert.EqualError(t, err, "Lexical error at line 1, column 7 - Unexpected character #\nLexical error at line 2, column 5 - Unterminated string")
+
+	var list ErrorList
+	assert.True(t, errors.As(err, &list))
+	assert.Len(t, list, 2)
+	var lexErr *LexicalError
+	assert.True(t, errors.As(err, &lexErr))
+	assert.Equal(t, 1, lexErr.Line)
+}
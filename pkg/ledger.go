@@ -0,0 +1,122 @@
+package uniris
+
+import (
+	"fmt"
+	"sync"
+)
+
+//Ledger gives access to the IRIS accounts and is implemented by the host
+type Ledger interface {
+	//Balance returns the IRIS balance of an account
+	Balance(address string) (Decimal, error)
+
+	//Transfer applies the transfers recorded by a successful execution.
+	//Either all the transfers are applied or none of them.
+	Transfer(transfers []Transfer) error
+}
+
+//Transfer is a movement of IRIS between two accounts
+type Transfer struct {
+	From   string
+	To     string
+	Amount Decimal
+}
+
+//MemoryLedger is a Ledger keeping the balances in memory
+type MemoryLedger struct {
+	mu       sync.Mutex
+	balances map[string]Decimal
+}
+
+//NewMemoryLedger creates an in-memory ledger with initial balances
+func NewMemoryLedger(balances map[string]Decimal) *MemoryLedger {
+	l := &MemoryLedger{
+		balances: make(map[string]Decimal, len(balances)),
+	}
+	for addr, b := range balances {
+		l.balances[addr] = b
+	}
+	return l
+}
+
+//Balance returns the IRIS balance of an account
+func (l *MemoryLedger) Balance(address string) (Decimal, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.balances[address], nil
+}
+
+//Transfer applies all the transfers or none of them if an account cannot afford them
+func (l *MemoryLedger) Transfer(transfers []Transfer) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	balances := make(map[string]Decimal, 0)
+	for _, t := range transfers {
+		if _, exist := balances[t.From]; !exist {
+			balances[t.From] = l.balances[t.From]
+		}
+		if _, exist := balances[t.To]; !exist {
+			balances[t.To] = l.balances[t.To]
+		}
+		if balances[t.From].Cmp(t.Amount) < 0 {
+			return fmt.Errorf("Insufficient balance on %s", t.From)
+		}
+		from, err := balances[t.From].Sub(t.Amount)
+		if err != nil {
+			return err
+		}
+		//The debit is recorded first so that a transfer to the same account leaves it unchanged
+		balances[t.From] = from
+		to, err := balances[t.To].Add(t.Amount)
+		if err != nil {
+			return err
+		}
+		balances[t.To] = to
+	}
+	for addr, b := range balances {
+		l.balances[addr] = b
+	}
+	return nil
+}
+
+//pendingTransfers records the transfers of an execution until it succeeds
+type pendingTransfers struct {
+	ledger    Ledger
+	account   string
+	transfers []Transfer
+}
+
+func (p *pendingTransfers) send(to string, amount Decimal) error {
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("Transfer amount must be positive, got %v", amount)
+	}
+	balance, err := p.ledger.Balance(p.account)
+	if err != nil {
+		return err
+	}
+	for _, t := range p.transfers {
+		if balance, err = balance.Sub(t.Amount); err != nil {
+			return err
+		}
+	}
+	if balance.Cmp(amount) < 0 {
+		return fmt.Errorf("Insufficient balance: %v available, %v requested", balance, amount)
+	}
+	p.transfers = append(p.transfers, Transfer{
+		From:   p.account,
+		To:     to,
+		Amount: amount,
+	})
+	return nil
+}
+
+func (p *pendingTransfers) commit() ([]Transfer, error) {
+	if len(p.transfers) == 0 {
+		return nil, nil
+	}
+	if err := p.ledger.Transfer(p.transfers); err != nil {
+		return nil, err
+	}
+	return p.transfers, nil
+}
@@ -0,0 +1,158 @@
+package uniris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLedgerTransfer(t *testing.T) {
+	l := NewMemoryLedger(map[string]Decimal{"alice": MustParseDecimal("10")})
+
+	err := l.Transfer([]Transfer{
+		Transfer{From: "alice", To: "bob", Amount: MustParseDecimal("4")},
+		Transfer{From: "bob", To: "carol", Amount: MustParseDecimal("1")},
+	})
+	assert.Nil(t, err)
+
+	balance, _ := l.Balance("alice")
+	assert.Equal(t, "6", balance.String())
+	balance, _ = l.Balance("bob")
+	assert.Equal(t, "3", balance.String())
+	balance, _ = l.Balance("carol")
+	assert.Equal(t, "1", balance.String())
+}
+
+func TestMemoryLedgerTransferIsAtomic(t *testing.T) {
+	l := NewMemoryLedger(map[string]Decimal{"alice": MustParseDecimal("10")})
+
+	err := l.Transfer([]Transfer{
+		Transfer{From: "alice", To: "bob", Amount: MustParseDecimal("4")},
+		Transfer{From: "alice", To: "bob", Amount: MustParseDecimal("7")},
+	})
+	assert.EqualError(t, err, "Insufficient balance on alice")
+
+	balance, _ := l.Balance("alice")
+	assert.Equal(t, "10", balance.String())
+	balance, _ = l.Balance("bob")
+	assert.Equal(t, "0", balance.String())
+}
+
+func TestMemoryLedgerSelfTransfer(t *testing.T) {
+	l := NewMemoryLedger(map[string]Decimal{"contract": MustParseDecimal("10")})
+
+	assert.Nil(t, l.Transfer([]Transfer{
+		Transfer{From: "contract", To: "contract", Amount: MustParseDecimal("5")},
+	}))
+	balance, _ := l.Balance("contract")
+	assert.Equal(t, "10", balance.String())
+
+	res, err := NewInterpreter(WithLedger(l, "contract")).Run("send(\"contract\", 5)")
+	assert.Nil(t, err)
+	assert.Len(t, res.Transfers, 1)
+	balance, _ = l.Balance("contract")
+	assert.Equal(t, "10", balance.String())
+}
+
+func TestPendingTransfers(t *testing.T) {
+	p := &pendingTransfers{
+		ledger:  NewMemoryLedger(map[string]Decimal{"contract": MustParseDecimal("10")}),
+		account: "contract",
+	}
+
+	assert.Nil(t, p.send("alice", MustParseDecimal("6")))
+	assert.EqualError(t, p.send("bob", MustParseDecimal("5")), "Insufficient balance: 4 available, 5 requested")
+	assert.EqualError(t, p.send("bob", MustParseDecimal("-1")), "Transfer amount must be positive, got -1")
+	assert.Len(t, p.transfers, 1)
+
+	transfers, err := p.commit()
+	assert.Nil(t, err)
+	assert.Len(t, transfers, 1)
+	assert.Equal(t, "alice", transfers[0].To)
+	assert.Equal(t, "6", transfers[0].Amount.String())
+}
+
+func TestInterpretSend(t *testing.T) {
+	ledger := NewMemoryLedger(map[string]Decimal{"contract": MustParseDecimal("10")})
+	env := NewEnvironment(nil)
+	env.SetLedger(ledger, "contract")
+
+	res, err := Interpret(`
+send("alice", 3)
+send("bob", 2)
+`, env)
+	assert.Nil(t, err)
+	assert.Len(t, res.Transfers, 2)
+	assert.Equal(t, "alice", res.Transfers[0].To)
+	assert.Equal(t, "3", res.Transfers[0].Amount.String())
+	assert.Equal(t, "bob", res.Transfers[1].To)
+	assert.Equal(t, "2", res.Transfers[1].Amount.String())
+
+	balance, _ := ledger.Balance("contract")
+	assert.Equal(t, "5", balance.String())
+}
+
+func TestInterpretFailedRunMovesNoFunds(t *testing.T) {
+	ledger := NewMemoryLedger(map[string]Decimal{"contract": MustParseDecimal("10")})
+	env := NewEnvironment(nil)
+	env.SetLedger(ledger, "contract")
+
+	_, err := Interpret(`
+send("alice", 3)
+send("bob", 8)
+`, env)
+	assert.EqualError(t, err, "Runtime error at send of line 3, column 1 - Insufficient balance: 7 available, 8 requested")
+
+	_, err = Interpret(`
+send("alice", 3)
+undefinedFunction()
+`, env)
+	assert.Error(t, err)
+
+	balance, _ := ledger.Balance("contract")
+	assert.Equal(t, "10", balance.String())
+	balance, _ = ledger.Balance("alice")
+	assert.Equal(t, "0", balance.String())
+}
+
+func TestContractCallSend(t *testing.T) {
+	ledger := NewMemoryLedger(map[string]Decimal{"contract": MustParseDecimal("10")})
+	env := NewEnvironment(nil)
+	env.SetLedger(ledger, "contract")
+
+	res, err := Interpret(`
+contract Faucet {
+    function claim(to) {
+        send(to, 1)
+    }
+}
+`, env)
+	assert.Nil(t, err)
+
+	_, err = res.Contract.Call("claim", String("alice"))
+	assert.Nil(t, err)
+	balance, _ := ledger.Balance("alice")
+	assert.Equal(t, "1", balance.String())
+}
+
+func TestSendWithoutLedger(t *testing.T) {
+	_, err := Interpret(`send("alice", 1)`, nil)
+	assert.EqualError(t, err, "Runtime error at send of line 1, column 1 - No ledger in the execution context")
+}
+
+func TestInterpretSendDecimal(t *testing.T) {
+	ledger := NewMemoryLedger(map[string]Decimal{"contract": MustParseDecimal("0.3")})
+	env := NewEnvironment(nil)
+	env.SetLedger(ledger, "contract")
+
+	_, err := Interpret(`
+send("alice", 0.1d)
+send("bob", 0.2)
+`, env)
+	assert.Nil(t, err)
+
+	balance, _ := ledger.Balance("contract")
+	assert.Equal(t, "0", balance.String())
+	balance, _ = ledger.Balance("bob")
+	assert.Equal(t, "0.2", balance.String())
+}
@@ -0,0 +1,102 @@
+package uniris
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+//list is an ordered collection of values shared by reference
+type list struct {
+	elements []Value
+}
+
+func newList(elements ...Value) *list {
+	if elements == nil {
+		elements = make([]Value, 0)
+	}
+	return &list{
+		elements: elements,
+	}
+}
+
+func (l *list) get(i Value) (Value, error) {
+	idx, err := l.index(i)
+	if err != nil {
+		return nil, err
+	}
+	return l.elements[idx], nil
+}
+
+func (l *list) set(i Value, value Value) error {
+	idx, err := l.index(i)
+	if err != nil {
+		return err
+	}
+	l.elements[idx] = value
+	return nil
+}
+
+func (l *list) index(i Value) (int, error) {
+	idx, err := toIndex(i)
+	if err != nil {
+		return 0, err
+	}
+	if idx < 0 || idx >= len(l.elements) {
+		return 0, fmt.Errorf("Index %d out of range [0:%d]", idx, len(l.elements))
+	}
+	return idx, nil
+}
+
+func (l *list) String() string {
+	return l.format(nil)
+}
+
+//format prints the list, a list holding itself is printed [...] where it is repeated
+func (l *list) format(printing map[Value]bool) string {
+	if printing[l] {
+		return "[...]"
+	}
+	if printing == nil {
+		printing = make(map[Value]bool, 0)
+	}
+	printing[l] = true
+	defer delete(printing, l)
+
+	elements := make([]string, len(l.elements))
+	for i, el := range l.elements {
+		elements[i] = repr(el, printing)
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+//toIndex converts a number value into a position of a list or a string.
+//The integers which do not fit an int are out of range of any list.
+func toIndex(i Value) (int, error) {
+	switch n := i.(type) {
+	case Float:
+		if n != Float(math.Trunc(float64(n))) {
+			return 0, fmt.Errorf("Index must be an integer, got %v", n)
+		}
+		if float64(n) < math.MinInt || float64(n) >= -math.MinInt {
+			return 0, fmt.Errorf("Index %v out of range", n)
+		}
+		return int(n), nil
+	case Int:
+		if int64(n) < math.MinInt || int64(n) > math.MaxInt {
+			return 0, fmt.Errorf("Index %v out of range", n)
+		}
+		return int(n), nil
+	case Decimal:
+		if n.scale != 0 {
+			return 0, fmt.Errorf("Index must be an integer, got %v", n)
+		}
+		v := n.value()
+		if !v.IsInt64() || v.Int64() < math.MinInt || v.Int64() > math.MaxInt {
+			return 0, fmt.Errorf("Index %v out of range", n)
+		}
+		return int(v.Int64()), nil
+	default:
+		return 0, fmt.Errorf("Index must be a number, got %v", i)
+	}
+}
@@ -0,0 +1,65 @@
+package uniris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListGet(t *testing.T) {
+	l := newList(Float(1), String("a"))
+	val, err := l.get(Float(1))
+	assert.Nil(t, err)
+	assert.Equal(t, String("a"), val)
+
+	_, err = l.get(Float(2))
+	assert.EqualError(t, err, "Index 2 out of range [0:2]")
+
+	_, err = l.get(Float(-1))
+	assert.EqualError(t, err, "Index -1 out of range [0:2]")
+
+	_, err = l.get(Float(0.5))
+	assert.EqualError(t, err, "Index must be an integer, got 0.5")
+
+	_, err = l.get(String("a"))
+	assert.EqualError(t, err, "Index must be a number, got a")
+
+	_, err = l.get(MustParseDecimal("18446744073709551616"))
+	assert.EqualError(t, err, "Index 18446744073709551616 out of range")
+
+	_, err = l.get(Float(1e30))
+	assert.EqualError(t, err, "Index 1e+30 out of range")
+}
+
+func TestListHugeIndex(t *testing.T) {
+	for _, code := range []string{"xs = [1, 2]\nxs[18446744073709551616d]", "xs = [1, 2]\nxs[18446744073709551616d] = 3"} {
+		_, err, _, vmErr := runBoth(t, code)
+		assert.EqualError(t, err, "Runtime error at ] of line 2, column 25 - Index 18446744073709551616 out of range")
+		assert.Equal(t, err, vmErr)
+	}
+}
+
+func TestListSet(t *testing.T) {
+	l := newList(Float(1))
+	assert.Nil(t, l.set(Float(0), String("b")))
+	assert.Equal(t, String("b"), l.elements[0])
+	assert.Error(t, l.set(Float(1), String("c")))
+}
+
+func TestListString(t *testing.T) {
+	assert.Equal(t, "[]", newList().String())
+	assert.Equal(t, "[1, \"a\", true, [2]]", newList(Float(1), String("a"), Bool(true), newList(Float(2))).String())
+
+	res, err := Interpret(`
+x = [1]
+push(x, x)
+x
+o = {a: x}
+o.self = o
+o
+y = [x, x]
+y
+`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "[1, [...]]\n{\"a\": [1, [...]], \"self\": {...}}\n[[1, [...]], [1, [...]]]\n", res.Output)
+}
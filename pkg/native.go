@@ -0,0 +1,120 @@
+package uniris
+
+import "fmt"
+
+//NativeFunc is a Go function registered by the host that the scripts can call.
+//The interpreter checks the arguments against its signature before calling it.
+type NativeFunc interface {
+	Name() string
+	Signature() Signature
+	Call(args ...Value) (Value, error)
+}
+
+//ParamType constrains the type of a native function parameter
+type ParamType string
+
+//Parameter types, named as in the error messages
+const (
+	TypeAny      ParamType = "any"
+	TypeNumber   ParamType = "number"
+	TypeDecimal  ParamType = "decimal"
+	TypeString   ParamType = "string"
+	TypeBoolean  ParamType = "boolean"
+	TypeList     ParamType = "list"
+	TypeObject   ParamType = "object"
+	TypeFunction ParamType = "function"
+)
+
+//Signature describes the parameters of a native function.
+//When Variadic is set, the last parameter can be repeated any number of times, including zero.
+//When Nondeterministic is set, the result does not depend on the arguments only and the function
+//is rejected in deterministic mode.
+type Signature struct {
+	Params           []ParamType
+	Variadic         bool
+	Nondeterministic bool
+}
+
+//check verifies the number and the types of the arguments of a call
+func (s Signature) check(name string, args []Value) error {
+	if s.Variadic && len(s.Params) > 0 {
+		if min := len(s.Params) - 1; len(args) < min {
+			return fmt.Errorf("%s expects at least %d arguments, got %d", name, min, len(args))
+		}
+	} else if err := checkArity(name, args, len(s.Params)); err != nil {
+		return err
+	}
+
+	for i, arg := range args {
+		param := s.Params[len(s.Params)-1]
+		if i < len(s.Params) {
+			param = s.Params[i]
+		}
+		if param != TypeAny && typeName(arg) != string(param) {
+			return fmt.Errorf("%s expects a %s as argument %d, got %s", name, param, i+1, typeName(arg))
+		}
+	}
+	return nil
+}
+
+//NewNative adapts a Go function into a NativeFunc
+func NewNative(name string, sig Signature, fn func(args ...Value) (Value, error)) NativeFunc {
+	return native{
+		name: name,
+		sig:  sig,
+		fn:   fn,
+	}
+}
+
+type native struct {
+	name string
+	sig  Signature
+	fn   func(args ...Value) (Value, error)
+}
+
+func (n native) Name() string {
+	return n.name
+}
+
+func (n native) Signature() Signature {
+	return n.sig
+}
+
+func (n native) Call(args ...Value) (Value, error) {
+	return n.fn(args...)
+}
+
+//nativeFunc makes a NativeFunc callable from the scripts under its qualified name
+type nativeFunc struct {
+	name string
+	fn   NativeFunc
+}
+
+func (f nativeFunc) Kind() Kind {
+	return KindFunction
+}
+
+func (f nativeFunc) String() string {
+	return fmt.Sprintf("<function %s>", f.name)
+}
+
+func (f nativeFunc) isValue() {}
+
+func (f nativeFunc) call(env *Environment, args ...Value) (Value, error) {
+	if err := f.fn.Signature().check(f.name, args); err != nil {
+		return nil, err
+	}
+	return f.fn.Call(args...)
+}
+
+//module exposes native functions as the members of an object, such as crypto.sha256
+func module(name string, funcs []NativeFunc) *object {
+	o := newObject()
+	for _, f := range funcs {
+		o.set(String(f.Name()), nativeFunc{
+			name: name + "." + f.Name(),
+			fn:   f,
+		})
+	}
+	return o
+}
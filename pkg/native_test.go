@@ -0,0 +1,112 @@
+package uniris
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureCheck(t *testing.T) {
+	fixed := Signature{Params: []ParamType{TypeString, TypeNumber}}
+	assert.Nil(t, fixed.check("f", []Value{String("a"), Int(1)}))
+	assert.EqualError(t, fixed.check("f", []Value{String("a")}), "f expects 2 arguments, got 1")
+	assert.EqualError(t, fixed.check("f", []Value{String("a"), String("b")}), "f expects a number as argument 2, got string")
+
+	variadic := Signature{Params: []ParamType{TypeString, TypeAny}, Variadic: true}
+	assert.Nil(t, variadic.check("g", []Value{String("a")}))
+	assert.Nil(t, variadic.check("g", []Value{String("a"), Int(1), Bool(true), nil}))
+	assert.EqualError(t, variadic.check("g", []Value{}), "g expects at least 1 arguments, got 0")
+	assert.EqualError(t, variadic.check("g", []Value{Int(1)}), "g expects a string as argument 1, got number")
+
+	numbers := Signature{Params: []ParamType{TypeNumber}, Variadic: true}
+	assert.EqualError(t, numbers.check("sum", []Value{Int(1), Float(2.5), String("3")}), "sum expects a number as argument 3, got string")
+}
+
+//joinFunc is a native function implementing NativeFunc directly
+type joinFunc struct{}
+
+func (f joinFunc) Name() string {
+	return "join"
+}
+
+func (f joinFunc) Signature() Signature {
+	return Signature{Params: []ParamType{TypeString, TypeString}, Variadic: true}
+}
+
+func (f joinFunc) Call(args ...Value) (Value, error) {
+	parts := make([]string, 0, len(args)-1)
+	for _, a := range args[1:] {
+		parts = append(parts, string(a.(String)))
+	}
+	return String(strings.Join(parts, string(args[0].(String)))), nil
+}
+
+func TestInterpreterNatives(t *testing.T) {
+	called := false
+	upper := NewNative("upper", Signature{Params: []ParamType{TypeString}}, func(args ...Value) (Value, error) {
+		called = true
+		return String(strings.ToUpper(string(args[0].(String)))), nil
+	})
+	in := NewInterpreter(WithNatives(upper, joinFunc{}))
+
+	res, err := in.Run("upper(\"abc\")\njoin(\"-\", \"a\", \"b\", \"c\")")
+	assert.Nil(t, err)
+	assert.Equal(t, "ABC\na-b-c\n", res.Output)
+
+	called = false
+	_, err = in.Run("upper(\"a\", \"b\")")
+	assert.EqualError(t, err, "Runtime error at upper of line 1, column 1 - upper expects 1 arguments, got 2")
+	assert.False(t, called)
+
+	_, err = in.Run("upper(1)")
+	assert.EqualError(t, err, "Runtime error at upper of line 1, column 1 - upper expects a string as argument 1, got number")
+	assert.False(t, called)
+}
+
+func TestInterpreterModules(t *testing.T) {
+	double := NewNative("double", Signature{Params: []ParamType{TypeNumber}}, func(args ...Value) (Value, error) {
+		return args[0].(Int) * 2, nil
+	})
+	in := NewInterpreter(
+		WithModule("math", double),
+		WithModule("strings", joinFunc{}),
+	)
+
+	res, err := in.Run("math.double(21)\nstrings.join(\", \", \"a\", \"b\")")
+	assert.Nil(t, err)
+	assert.Equal(t, "42\na, b\n", res.Output)
+
+	_, err = in.Run("math.double(\"x\")")
+	assert.EqualError(t, err, "Runtime error at ) of line 1, column 16 - math.double expects a number as argument 1, got string")
+
+	_, err = in.Run("math.double = 1")
+	assert.Nil(t, err)
+	res, err = in.Run("math.double(1)")
+	assert.Nil(t, err)
+	assert.Equal(t, "2\n", res.Output)
+}
+
+func TestNondeterministicNatives(t *testing.T) {
+	random := NewNative("random", Signature{Nondeterministic: true}, func(args ...Value) (Value, error) {
+		return Int(4), nil
+	})
+	in := NewInterpreter(WithNatives(random), WithModule("rand", random), WithDeterministic(true))
+
+	for _, code := range []string{"random()", "rand.random()"} {
+		b, err := Compile(code)
+		assert.Nil(t, err)
+		_, err = in.Run(code)
+		assert.Error(t, err)
+		_, vmErr := in.RunBytecode(b)
+		assert.Equal(t, err, vmErr)
+	}
+	_, err := in.Run("random()")
+	assert.EqualError(t, err, "Runtime error at random of line 1, column 1 - random cannot be called in deterministic mode")
+	_, err = in.Run("rand.random()")
+	assert.EqualError(t, err, "Runtime error at ) of line 1, column 13 - Function cannot be called in deterministic mode")
+
+	res, err := NewInterpreter(WithNatives(random)).Run("random()")
+	assert.Nil(t, err)
+	assert.Equal(t, "4\n", res.Output)
+}
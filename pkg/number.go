@@ -0,0 +1,123 @@
+package uniris
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+//isNumber reports whether a value is an integer or a float
+func isNumber(v Value) bool {
+	switch KindOf(v) {
+	case KindInt, KindFloat:
+		return true
+	default:
+		return false
+	}
+}
+
+//toFloat promotes an integer to a float
+func toFloat(v Value) float64 {
+	if i, ok := v.(Int); ok {
+		return float64(i)
+	}
+	return float64(v.(Float))
+}
+
+//numberOperation applies an arithmetic or a comparison operator on two numbers.
+//Integers stay integers unless divided with a remainder, as soon as a float is involved both operands are promoted to float.
+func numberOperation(op token, left Value, right Value) (Value, error) {
+	if !isNumber(left) || !isNumber(right) {
+		return nil, operandError(op, left, right)
+	}
+
+	if left.Kind() == KindInt && right.Kind() == KindInt {
+		return integerOperation(op, int64(left.(Int)), int64(right.(Int)))
+	}
+	return floatOperation(op, toFloat(left), toFloat(right))
+}
+
+func integerOperation(op token, l int64, r int64) (Value, error) {
+	switch op.Type {
+	case TokenPlus:
+		res := l + r
+		if (l > 0 && r > 0 && res < 0) || (l < 0 && r < 0 && res >= 0) {
+			return nil, integerOverflow(op, l, r)
+		}
+		return Int(res), nil
+	case TokenMinus:
+		res := l - r
+		if (l >= 0 && r < 0 && res < 0) || (l < 0 && r > 0 && res >= 0) {
+			return nil, integerOverflow(op, l, r)
+		}
+		return Int(res), nil
+	case TokenStar:
+		if l == 0 || r == 0 {
+			return Int(0), nil
+		}
+		res := l * r
+		if res/r != l || (l == -1 && r == math.MinInt64) || (r == -1 && l == math.MinInt64) {
+			return nil, integerOverflow(op, l, r)
+		}
+		return Int(res), nil
+	case TokenSlash:
+		if r == 0 {
+			return nil, errors.New("Division by zero")
+		}
+		if l == math.MinInt64 && r == -1 {
+			return nil, integerOverflow(op, l, r)
+		}
+		//The division stays exact: an integer when there is no remainder, a float otherwise. div truncates.
+		if l%r != 0 {
+			return Float(float64(l) / float64(r)), nil
+		}
+		return Int(l / r), nil
+	case TokenPercent:
+		if r == 0 {
+			return nil, errors.New("Division by zero")
+		}
+		if r == -1 {
+			return Int(0), nil
+		}
+		return Int(l % r), nil
+	case TokenGreater:
+		return Bool(l > r), nil
+	case TokenGreaterEqual:
+		return Bool(l >= r), nil
+	case TokenLess:
+		return Bool(l < r), nil
+	case TokenLessEqual:
+		return Bool(l <= r), nil
+	default:
+		return nil, errors.New("Not supported as binary expression")
+	}
+}
+
+func floatOperation(op token, l float64, r float64) (Value, error) {
+	switch op.Type {
+	case TokenPlus:
+		return Float(l + r), nil
+	case TokenMinus:
+		return Float(l - r), nil
+	case TokenStar:
+		return Float(l * r), nil
+	case TokenSlash:
+		return Float(l / r), nil
+	case TokenPercent:
+		return Float(math.Mod(l, r)), nil
+	case TokenGreater:
+		return Bool(l > r), nil
+	case TokenGreaterEqual:
+		return Bool(l >= r), nil
+	case TokenLess:
+		return Bool(l < r), nil
+	case TokenLessEqual:
+		return Bool(l <= r), nil
+	default:
+		return nil, errors.New("Not supported as binary expression")
+	}
+}
+
+func integerOverflow(op token, l int64, r int64) error {
+	return fmt.Errorf("Integer overflow: %d %s %d", l, op.Lexeme, r)
+}
@@ -0,0 +1,105 @@
+package uniris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegerOperation(t *testing.T) {
+	val, err := numberOperation(token{Type: TokenSlash, Lexeme: "/"}, Int(7), Int(2))
+	assert.Nil(t, err)
+	assert.Equal(t, Float(3.5), val)
+
+	val, err = numberOperation(token{Type: TokenPercent, Lexeme: "%"}, Int(-7), Int(3))
+	assert.Nil(t, err)
+	assert.Equal(t, Int(-1), val)
+
+	_, err = numberOperation(token{Type: TokenPercent, Lexeme: "%"}, Int(1), Int(0))
+	assert.EqualError(t, err, "Division by zero")
+
+	_, err = numberOperation(token{Type: TokenSlash, Lexeme: "/"}, Int(1), Int(0))
+	assert.EqualError(t, err, "Division by zero")
+}
+
+func TestIntegerOverflow(t *testing.T) {
+	_, err := numberOperation(token{Type: TokenPlus, Lexeme: "+"}, Int(9223372036854775807), Int(1))
+	assert.EqualError(t, err, "Integer overflow: 9223372036854775807 + 1")
+
+	_, err = numberOperation(token{Type: TokenMinus, Lexeme: "-"}, Int(-9223372036854775807), Int(2))
+	assert.EqualError(t, err, "Integer overflow: -9223372036854775807 - 2")
+
+	_, err = numberOperation(token{Type: TokenStar, Lexeme: "*"}, Int(4611686018427387904), Int(2))
+	assert.EqualError(t, err, "Integer overflow: 4611686018427387904 * 2")
+
+	_, err = numberOperation(token{Type: TokenSlash, Lexeme: "/"}, Int(-9223372036854775808), Int(-1))
+	assert.EqualError(t, err, "Integer overflow: -9223372036854775808 / -1")
+}
+
+func TestNumberPromotion(t *testing.T) {
+	val, err := numberOperation(token{Type: TokenSlash, Lexeme: "/"}, Int(7), Float(2))
+	assert.Nil(t, err)
+	assert.Equal(t, Float(3.5), val)
+
+	val, err = numberOperation(token{Type: TokenPercent, Lexeme: "%"}, Float(7.5), Int(2))
+	assert.Nil(t, err)
+	assert.Equal(t, Float(1.5), val)
+
+	val, err = numberOperation(token{Type: TokenLess, Lexeme: "<"}, Int(1), Float(1.5))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(true), val)
+
+	_, err = numberOperation(token{Type: TokenMinus, Lexeme: "-"}, String("a"), Int(1))
+	assert.EqualError(t, err, "Cannot subtract number from string")
+}
+
+func TestIntegerDivision(t *testing.T) {
+	//Before the integer type 7 / 2 gave 3.5, the division of integers is still exact
+	res, err := Interpret(`
+7 / 2
+6 / 3
+a = -7 / 2
+a
+div(7, 2)
+div(-7, 2)
+div(6, 3)
+`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "3.5\n2\n-3.5\n3\n-3\n2\n", res.Output)
+
+	val, err := numberOperation(token{Type: TokenSlash, Lexeme: "/"}, Int(6), Int(3))
+	assert.Nil(t, err)
+	assert.Equal(t, Int(2), val)
+	val, err = numberOperation(token{Type: TokenSlash, Lexeme: "/"}, Int(1), Int(4))
+	assert.Nil(t, err)
+	assert.Equal(t, Float(0.25), val)
+
+	_, err = Interpret("div(7, 2.0)", nil)
+	assert.EqualError(t, err, "Runtime error at div of line 1, column 1 - div expects two integers, got integer and float")
+	_, err = Interpret("div(1, 0)", nil)
+	assert.EqualError(t, err, "Runtime error at div of line 1, column 1 - Division by zero")
+	_, err = Interpret("div(-9223372036854775807 - 1, -1)", nil)
+	assert.EqualError(t, err, "Runtime error at div of line 1, column 1 - Integer overflow: div(-9223372036854775808, -1)")
+}
+
+func TestInterpretIntegers(t *testing.T) {
+	res, err := Interpret(`
+10 % 3
+7 / 2
+7.0 / 2
+1 == 1.0
+2 * 1.5d
+sum = 0
+for i = 0; i < 10; i = i + 1 {
+    if i % 2 == 0 {
+        sum = sum + i
+    }
+}
+sum
+`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "1\n3.5\n3.5\ntrue\n3\n20\n", res.Output)
+
+	_, err = Interpret("9223372036854775807 + 1", nil)
+	assert.EqualError(t, err, "Runtime error at + of line 1, column 21 - Integer overflow: 9223372036854775807 + 1")
+}
@@ -0,0 +1,99 @@
+package uniris
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//object is a collection of key/value pairs shared by reference
+//Keys are kept in insertion order so iteration and printing are stable
+type object struct {
+	keys     []Value
+	values   map[mapKey]Value
+	readOnly bool
+}
+
+func newObject() *object {
+	return &object{
+		keys:   make([]Value, 0),
+		values: make(map[mapKey]Value, 0),
+	}
+}
+
+func (o *object) get(key Value) (Value, error) {
+	k, err := keyOf(key)
+	if err != nil {
+		return nil, err
+	}
+	v, exist := o.values[k]
+	if !exist {
+		return nil, fmt.Errorf("Undefined key %s", key)
+	}
+	return v, nil
+}
+
+func (o *object) set(key Value, value Value) error {
+	if o.readOnly {
+		return errors.New("Cannot modify a read-only object")
+	}
+	k, err := keyOf(key)
+	if err != nil {
+		return err
+	}
+	if _, exist := o.values[k]; !exist {
+		o.keys = append(o.keys, key)
+	}
+	o.values[k] = value
+	return nil
+}
+
+func (o *object) has(key Value) bool {
+	k, err := keyOf(key)
+	if err != nil {
+		return false
+	}
+	_, exist := o.values[k]
+	return exist
+}
+
+//lookup returns the value of one of the object keys
+func (o *object) lookup(key Value) Value {
+	k, _ := keyOf(key)
+	return o.values[k]
+}
+
+func (o *object) String() string {
+	return o.format(nil)
+}
+
+//format prints the object, an object holding itself is printed {...} where it is repeated
+func (o *object) format(printing map[Value]bool) string {
+	if printing[o] {
+		return "{...}"
+	}
+	if printing == nil {
+		printing = make(map[Value]bool, 0)
+	}
+	printing[o] = true
+	defer delete(printing, o)
+
+	pairs := make([]string, len(o.keys))
+	for i, k := range o.keys {
+		pairs[i] = fmt.Sprintf("%s: %s", repr(k, printing), repr(o.lookup(k), printing))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+//repr formats a value nested inside the lists and objects being printed
+func repr(val Value, printing map[Value]bool) string {
+	switch v := val.(type) {
+	case String:
+		return fmt.Sprintf("%q", string(v))
+	case *list:
+		return v.format(printing)
+	case *object:
+		return v.format(printing)
+	}
+	return format(val)
+}
@@ -0,0 +1,50 @@
+package uniris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectGetSet(t *testing.T) {
+	o := newObject()
+	assert.Nil(t, o.set(String("b"), Float(1)))
+	assert.Nil(t, o.set(String("a"), String("x")))
+	assert.Nil(t, o.set(String("b"), Float(2)))
+	assert.Equal(t, []Value{String("b"), String("a")}, o.keys)
+
+	val, err := o.get(String("b"))
+	assert.Nil(t, err)
+	assert.Equal(t, Float(2), val)
+
+	_, err = o.get(String("c"))
+	assert.EqualError(t, err, "Undefined key c")
+
+	assert.Nil(t, o.set(Float(1), String("y")))
+	val, err = o.get(Int(1))
+	assert.Nil(t, err)
+	assert.Equal(t, String("y"), val)
+
+	assert.EqualError(t, o.set(newList(), String("x")), "Cannot use a list as a key")
+	assert.EqualError(t, o.set(nil, String("x")), "Cannot use nil as a key")
+}
+
+func TestObjectHas(t *testing.T) {
+	o := newObject()
+	o.set(String("a"), nil)
+	assert.True(t, o.has(String("a")))
+	assert.False(t, o.has(String("b")))
+	assert.False(t, o.has(Float(1)))
+	assert.False(t, o.has(newList()))
+}
+
+func TestObjectString(t *testing.T) {
+	o := newObject()
+	assert.Equal(t, "{}", o.String())
+	o.set(String("name"), String("alice"))
+	o.set(String("tags"), newList(String("a")))
+	assert.Equal(t, "{\"name\": \"alice\", \"tags\": [\"a\"]}", o.String())
+
+	o.set(Int(1), Bool(true))
+	assert.Equal(t, "{\"name\": \"alice\", \"tags\": [\"a\"], 1: true}", o.String())
+}
@@ -0,0 +1,195 @@
+package uniris
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+//Logger receives the outcome of each execution, a *log.Logger fits
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+//Interpreter runs smart contract codes with the configuration given at its creation.
+//The variables and functions declared by a run are kept for the next ones.
+//An Interpreter must not be used by several goroutines at the same time.
+type Interpreter struct {
+	env     *Environment
+	natives []NativeFunc
+	modules map[string][]NativeFunc
+	logger  Logger
+}
+
+//Option configures an Interpreter
+type Option func(*Interpreter)
+
+//NewInterpreter creates an interpreter configured by options
+func NewInterpreter(opts ...Option) *Interpreter {
+	in := &Interpreter{
+		env:     NewEnvironment(nil),
+		modules: make(map[string][]NativeFunc),
+	}
+	for _, opt := range opts {
+		opt(in)
+	}
+	return in
+}
+
+//WithOutput defines where print writes, the standard output by default
+func WithOutput(w io.Writer) Option {
+	return func(in *Interpreter) {
+		in.env.SetOutput(w)
+	}
+}
+
+//WithNative registers a Go function the scripts can call by name with any arguments
+func WithNative(name string, fn func(args ...Value) (Value, error)) Option {
+	return WithNatives(NewNative(name, Signature{Params: []ParamType{TypeAny}, Variadic: true}, fn))
+}
+
+//WithNatives registers native functions the scripts can call by name
+func WithNatives(funcs ...NativeFunc) Option {
+	return func(in *Interpreter) {
+		in.natives = append(in.natives, funcs...)
+	}
+}
+
+//WithModule registers native functions the scripts call as members of the module, such as crypto.sha256
+func WithModule(name string, funcs ...NativeFunc) Option {
+	return func(in *Interpreter) {
+		in.modules[name] = append(in.modules[name], funcs...)
+	}
+}
+
+//WithGasLimit defines the maximum gas of each execution, zero means unlimited
+func WithGasLimit(limit uint64) Option {
+	return func(in *Interpreter) {
+		in.env.SetGasLimit(limit)
+	}
+}
+
+//WithClock defines the function giving the current unix timestamp returned by now()
+func WithClock(clock func() int64) Option {
+	return func(in *Interpreter) {
+		in.env.SetClock(clock)
+	}
+}
+
+//WithDeterministic enables the deterministic mode, see Environment.SetDeterministic
+func WithDeterministic(deterministic bool) Option {
+	return func(in *Interpreter) {
+		in.env.SetDeterministic(deterministic)
+	}
+}
+
+//WithLedger defines the ledger used to send IRIS from the account of the contract
+func WithLedger(ledger Ledger, account string) Option {
+	return func(in *Interpreter) {
+		in.env.SetLedger(ledger, account)
+	}
+}
+
+//WithStateStore defines the store keeping the contract state variables between the runs, see Environment.SetStateStore
+func WithStateStore(store StateStore, id string) Option {
+	return func(in *Interpreter) {
+		in.env.SetStateStore(store, id)
+	}
+}
+
+//WithLogger defines the logger receiving the outcome of each execution
+func WithLogger(logger Logger) Option {
+	return func(in *Interpreter) {
+		in.logger = logger
+	}
+}
+
+//Environment returns the environment shared by the runs, to set a transaction or secrets for instance
+func (in *Interpreter) Environment() *Environment {
+	return in.env
+}
+
+//Run interprets a smart contract code
+func (in *Interpreter) Run(code string) (*Result, error) {
+	return in.RunContext(context.Background(), code)
+}
+
+//RunContext interprets a smart contract code until the context is canceled or times out
+func (in *Interpreter) RunContext(ctx context.Context, code string) (*Result, error) {
+	res, err := interpret(ctx, code, in.env, in.globals())
+	in.log(res, err)
+	return res, err
+}
+
+//RunBytecode executes a compiled smart contract code
+func (in *Interpreter) RunBytecode(b *Bytecode) (*Result, error) {
+	return in.RunBytecodeContext(context.Background(), b)
+}
+
+//RunBytecodeContext executes a compiled smart contract code until the context is canceled or times out
+func (in *Interpreter) RunBytecodeContext(ctx context.Context, b *Bytecode) (*Result, error) {
+	res, err := b.run(ctx, in.env, in.globals())
+	in.log(res, err)
+	return res, err
+}
+
+//Call executes a function declared by a previous run
+func (in *Interpreter) Call(name string, args ...Value) (Value, error) {
+	return in.CallContext(context.Background(), name, args...)
+}
+
+//CallContext executes a function declared by a previous run until the context is canceled or times out
+func (in *Interpreter) CallContext(ctx context.Context, name string, args ...Value) (Value, error) {
+	env, exec := startExecution(ctx, in.env, in.globals())
+
+	res := &Result{}
+	val, err := in.call(env, name, args)
+	if err == nil {
+		err = res.finish(exec)
+	}
+	if err != nil {
+		in.log(nil, err)
+		return nil, err
+	}
+	in.log(res, nil)
+	return val, nil
+}
+
+//globals returns the natives and the modules registered by the host.
+//The modules are created for each run so that a script cannot alter them for the next ones.
+func (in *Interpreter) globals() map[string]Value {
+	globals := make(map[string]Value, len(in.natives)+len(in.modules))
+	for _, f := range in.natives {
+		globals[f.Name()] = nativeFunc{
+			name: f.Name(),
+			fn:   f,
+		}
+	}
+	for name, funcs := range in.modules {
+		globals[name] = module(name, funcs)
+	}
+	return globals
+}
+
+func (in *Interpreter) call(env *Environment, name string, args []Value) (Value, error) {
+	v, err := env.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := v.(callable)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a function, got %s", name, typeName(v))
+	}
+	return f.call(env, args...)
+}
+
+func (in *Interpreter) log(res *Result, err error) {
+	if in.logger == nil {
+		return
+	}
+	if err != nil {
+		in.logger.Printf("Execution failed: %s", err)
+		return
+	}
+	in.logger.Printf("Execution succeeded: %d gas used, %d transfers", res.GasUsed, len(res.Transfers))
+}
@@ -0,0 +1,121 @@
+package uniris
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestInterpreterOutput(t *testing.T) {
+	var out bytes.Buffer
+	in := NewInterpreter(WithOutput(&out))
+
+	res, err := in.Run("print 1 + 1\n\"value\"")
+	assert.Nil(t, err)
+	assert.Equal(t, "2\n", out.String())
+	assert.Equal(t, "value\n", res.Output)
+
+	b, err := Compile("print \"vm\"")
+	assert.Nil(t, err)
+	_, err = in.RunBytecode(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "2\nvm\n", out.String())
+}
+
+func TestInterpreterNative(t *testing.T) {
+	in := NewInterpreter(WithNative("upper", func(args ...Value) (Value, error) {
+		if len(args) != 1 {
+			return nil, errors.New("upper expects 1 argument")
+		}
+		return String(strings.ToUpper(args[0].String())), nil
+	}))
+
+	res, err := in.Run("upper(\"abc\")")
+	assert.Nil(t, err)
+	assert.Equal(t, "ABC\n", res.Output)
+
+	_, err = in.Run("upper()")
+	assert.EqualError(t, err, "Runtime error at upper of line 1, column 1 - upper expects 1 argument")
+
+	_, err = Interpret("upper(\"abc\")", nil)
+	assert.EqualError(t, err, "Runtime error at upper of line 1, column 1 - Undefined variable upper")
+}
+
+func TestInterpreterGasLimitAndClock(t *testing.T) {
+	in := NewInterpreter(
+		WithGasLimit(100),
+		WithDeterministic(true),
+		WithClock(func() int64 { return 1000 }),
+	)
+
+	res, err := in.Run("now()")
+	assert.Nil(t, err)
+	assert.Equal(t, "1000\n", res.Output)
+
+	_, err = in.Run("while true {}")
+	var outOfGas *OutOfGasError
+	assert.True(t, errors.As(err, &outOfGas))
+	assert.Equal(t, uint64(100), outOfGas.Limit)
+}
+
+func TestInterpreterLedger(t *testing.T) {
+	ledger := NewMemoryLedger(map[string]Decimal{"contract": MustParseDecimal("10")})
+	in := NewInterpreter(WithLedger(ledger, "contract"))
+
+	res, err := in.Run("send(\"alice\", 4)")
+	assert.Nil(t, err)
+	assert.Len(t, res.Transfers, 1)
+	balance, _ := ledger.Balance("alice")
+	assert.Equal(t, "4", balance.String())
+}
+
+func TestInterpreterCall(t *testing.T) {
+	logger := &testLogger{}
+	in := NewInterpreter(WithLogger(logger))
+
+	_, err := in.Run(`
+total = 0
+function add(n) {
+    total = total + n
+    return total
+}
+`)
+	assert.Nil(t, err)
+
+	val, err := in.Call("add", Int(2))
+	assert.Nil(t, err)
+	assert.Equal(t, Int(2), val)
+	val, err = in.Call("add", Int(3))
+	assert.Nil(t, err)
+	assert.Equal(t, Int(5), val)
+
+	res, err := in.Run("total")
+	assert.Nil(t, err)
+	assert.Equal(t, "5\n", res.Output)
+
+	_, err = in.Call("total")
+	assert.EqualError(t, err, "total is not a function, got number")
+	_, err = in.Call("unknown")
+	assert.EqualError(t, err, "Undefined variable unknown")
+
+	assert.Equal(t, []string{
+		"Execution succeeded: 10 gas used, 0 transfers",
+		"Execution succeeded: 17 gas used, 0 transfers",
+		"Execution succeeded: 17 gas used, 0 transfers",
+		"Execution succeeded: 1 gas used, 0 transfers",
+		"Execution failed: total is not a function, got number",
+		"Execution failed: Undefined variable unknown",
+	}, logger.lines)
+}
@@ -0,0 +1,213 @@
+package uniris
+
+import (
+	"fmt"
+
+	"github.com/uniris/uniris-interpreter/pkg/ast"
+)
+
+//Parse returns the syntax tree of a code or all its lexical or syntax errors as an ErrorList
+func Parse(code string) (*ast.Program, error) {
+	stmts, err := parseCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	prog := &ast.Program{
+		Statements: make([]ast.Statement, len(stmts)),
+	}
+	for i, s := range stmts {
+		prog.Statements[i] = toASTStatement(s)
+	}
+	if len(stmts) > 0 {
+		prog.Span = ast.Span(join(stmts[0].span(), stmts[len(stmts)-1].span()))
+	}
+	return prog, nil
+}
+
+//parseCode scans and parses a code
+func parseCode(code string) ([]statement, error) {
+	sc := newScanner(code)
+	tokens := sc.scanTokens()
+	if len(sc.errs) > 0 {
+		return nil, ErrorList(sc.errs)
+	}
+	p := parser{
+		tokens: tokens,
+	}
+	return p.parse()
+}
+
+func toASTIdent(tok token) *ast.Ident {
+	return &ast.Ident{
+		Span: ast.Span(tokenSpan(tok)),
+		Name: tok.Lexeme,
+	}
+}
+
+func toASTStatement(stmt statement) ast.Statement {
+	span := ast.Span(stmt.span())
+	switch s := stmt.(type) {
+	case expressionStmt:
+		return &ast.ExpressionStmt{Span: span, X: toASTExpression(s.exp)}
+	case printStmt:
+		return &ast.PrintStmt{Span: span, X: toASTExpression(s.exp)}
+	case blockStmt:
+		return toASTBlock(s)
+	case ifStatement:
+		n := &ast.IfStmt{
+			Span: span,
+			Cond: toASTExpression(s.cond),
+			Then: toASTStatement(s.thenStmt),
+		}
+		if s.elseStmt != nil {
+			n.Else = toASTStatement(s.elseStmt)
+		}
+		return n
+	case whileStatement:
+		return &ast.WhileStmt{
+			Span: span,
+			Cond: toASTExpression(s.cond),
+			Body: toASTStatement(s.body),
+		}
+	case forStatement:
+		n := &ast.ForStmt{
+			Span: span,
+			Body: toASTStatement(s.body),
+		}
+		if s.init != nil {
+			n.Init = toASTStatement(s.init)
+		}
+		if s.cond != nil {
+			n.Cond = toASTExpression(s.cond)
+		}
+		if s.increment != nil {
+			n.Post = toASTExpression(s.increment)
+		}
+		return n
+	case forInStatement:
+		return &ast.ForInStmt{
+			Span:       span,
+			Name:       toASTIdent(s.name),
+			Collection: toASTExpression(s.collection),
+			Body:       toASTStatement(s.body),
+		}
+	case funcStatement:
+		return toASTFunction(s)
+	case contractStatement:
+		n := &ast.ContractDecl{
+			Span:      span,
+			Name:      toASTIdent(s.name),
+			State:     make([]*ast.AssignExpr, len(s.state)),
+			Functions: make([]*ast.FuncDecl, len(s.functions)),
+		}
+		for i, a := range s.state {
+			n.State[i] = toASTExpression(a).(*ast.AssignExpr)
+		}
+		for i, f := range s.functions {
+			n.Functions[i] = toASTFunction(f)
+		}
+		return n
+	case returnStatement:
+		return &ast.ReturnStmt{Span: span, Value: toASTExpression(s.value)}
+	case expression:
+		//The initializer of a for loop is an expression used as a statement
+		return &ast.ExpressionStmt{Span: span, X: toASTExpression(s)}
+	default:
+		panic(fmt.Sprintf("Unexpected statement %T", stmt))
+	}
+}
+
+func toASTBlock(b blockStmt) *ast.BlockStmt {
+	n := &ast.BlockStmt{
+		Span:       ast.Span(b.span()),
+		Statements: make([]ast.Statement, len(b.statements)),
+	}
+	for i, s := range b.statements {
+		n.Statements[i] = toASTStatement(s)
+	}
+	return n
+}
+
+func toASTFunction(f funcStatement) *ast.FuncDecl {
+	n := &ast.FuncDecl{
+		Span:   ast.Span(f.span()),
+		Name:   toASTIdent(f.name),
+		Params: make([]*ast.Ident, len(f.params)),
+		Body:   toASTBlock(f.body),
+	}
+	for i, p := range f.params {
+		n.Params[i] = toASTIdent(p)
+	}
+	return n
+}
+
+func toASTExpressions(exps []expression) []ast.Expression {
+	nodes := make([]ast.Expression, len(exps))
+	for i, e := range exps {
+		nodes[i] = toASTExpression(e)
+	}
+	return nodes
+}
+
+func toASTExpression(exp expression) ast.Expression {
+	span := ast.Span(exp.span())
+	switch e := exp.(type) {
+	case assignExpression:
+		return &ast.AssignExpr{Span: span, Name: toASTIdent(e.op), Value: toASTExpression(e.exp)}
+	case variableExpression:
+		return toASTIdent(e.op)
+	case binaryExpression:
+		return &ast.BinaryExpr{
+			Span:     span,
+			Left:     toASTExpression(e.left),
+			Operator: e.op.Lexeme,
+			Right:    toASTExpression(e.right),
+		}
+	case logicalExpression:
+		return &ast.LogicalExpr{
+			Span:     span,
+			Left:     toASTExpression(e.left),
+			Operator: e.op.Lexeme,
+			Right:    toASTExpression(e.right),
+		}
+	case unaryExpression:
+		return &ast.UnaryExpr{Span: span, Operator: e.op.Lexeme, X: toASTExpression(e.right)}
+	case groupingExpression:
+		return &ast.GroupExpr{Span: span, X: toASTExpression(e.exp)}
+	case literalExpression:
+		return &ast.Literal{Span: span, Value: ToGo(e.value)}
+	case callExpression:
+		return &ast.CallExpr{Span: span, Callee: toASTExpression(e.callee), Args: toASTExpressions(e.args)}
+	case listExpression:
+		return &ast.ListExpr{Span: span, Elements: toASTExpressions(e.elements)}
+	case objectExpression:
+		return &ast.ObjectExpr{
+			Span:   span,
+			Keys:   append([]string{}, e.keys...),
+			Values: toASTExpressions(e.values),
+		}
+	case indexExpression:
+		return &ast.IndexExpr{Span: span, X: toASTExpression(e.object), Index: toASTExpression(e.index)}
+	case indexAssignExpression:
+		return &ast.IndexAssignExpr{
+			Span:  span,
+			X:     toASTExpression(e.object),
+			Index: toASTExpression(e.index),
+			Value: toASTExpression(e.value),
+		}
+	case getExpression:
+		return &ast.GetExpr{Span: span, X: toASTExpression(e.object), Name: toASTIdent(e.name)}
+	case setExpression:
+		return &ast.SetExpr{
+			Span:  span,
+			X:     toASTExpression(e.object),
+			Name:  toASTIdent(e.name),
+			Value: toASTExpression(e.value),
+		}
+	case transactionExpression:
+		return &ast.TransactionExpr{Span: span}
+	default:
+		panic(fmt.Sprintf("Unexpected expression %T", exp))
+	}
+}
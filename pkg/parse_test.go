@@ -0,0 +1,94 @@
+package uniris
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uniris/uniris-interpreter/pkg/ast"
+)
+
+func TestParse(t *testing.T) {
+	prog, err := Parse("a = 1 + 2\nprint a")
+	assert.Nil(t, err)
+	assert.Len(t, prog.Statements, 2)
+	assert.Equal(t, ast.Span{Line: 1, Column: 1, Start: 0, End: 17}, prog.Span)
+
+	stmt := prog.Statements[0].(*ast.ExpressionStmt)
+	assign := stmt.X.(*ast.AssignExpr)
+	assert.Equal(t, "a", assign.Name.Name)
+	bin := assign.Value.(*ast.BinaryExpr)
+	assert.Equal(t, "+", bin.Operator)
+	assert.Equal(t, int64(1), bin.Left.(*ast.Literal).Value)
+	assert.Equal(t, ast.Span{Line: 1, Column: 5, Start: 4, End: 9}, bin.Span)
+
+	print := prog.Statements[1].(*ast.PrintStmt)
+	assert.Equal(t, "a", print.X.(*ast.Ident).Name)
+}
+
+func TestParseFor(t *testing.T) {
+	prog, err := Parse("for i = 0; i < 3; i = i + 1 { print i }")
+	assert.Nil(t, err)
+
+	loop := prog.Statements[0].(*ast.ForStmt)
+	assert.Equal(t, ast.Span{Line: 1, Column: 1, Start: 0, End: 39}, loop.Span)
+	init := loop.Init.(*ast.ExpressionStmt)
+	assert.Equal(t, "i", init.X.(*ast.AssignExpr).Name.Name)
+	assert.Equal(t, ast.Span{Line: 1, Column: 5, Start: 4, End: 9}, init.Span)
+	assert.Equal(t, "<", loop.Cond.(*ast.BinaryExpr).Operator)
+	assert.Equal(t, "i", loop.Post.(*ast.AssignExpr).Name.Name)
+	assert.IsType(t, &ast.PrintStmt{}, loop.Body.(*ast.BlockStmt).Statements[0])
+
+	idents := make([]string, 0)
+	ast.Inspect(prog, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			idents = append(idents, id.Name)
+		}
+		return true
+	})
+	assert.Equal(t, []string{"i", "i", "i", "i", "i"}, idents)
+
+	prog, err = Parse("for ; ; i = i + 1 { print i }")
+	assert.Nil(t, err)
+	loop = prog.Statements[0].(*ast.ForStmt)
+	assert.Nil(t, loop.Init)
+	assert.Nil(t, loop.Cond)
+	assert.NotNil(t, loop.Post)
+}
+
+func TestParseContract(t *testing.T) {
+	prog, err := Parse(`
+contract Wallet {
+    balance = 0
+    function deposit(amount) {
+        balance = balance + amount
+    }
+}
+`)
+	assert.Nil(t, err)
+
+	c := prog.Statements[0].(*ast.ContractDecl)
+	assert.Equal(t, "Wallet", c.Name.Name)
+	assert.Equal(t, "balance", c.State[0].Name.Name)
+	assert.Equal(t, "deposit", c.Functions[0].Name.Name)
+	assert.Equal(t, "amount", c.Functions[0].Params[0].Name)
+
+	idents := 0
+	ast.Inspect(prog, func(n ast.Node) bool {
+		if _, ok := n.(*ast.Ident); ok {
+			idents++
+		}
+		return true
+	})
+	assert.Equal(t, 7, idents)
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse("a = \nb = )")
+	var syntaxErr *SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+
+	_, err = Parse("a = @")
+	var lexErr *LexicalError
+	assert.True(t, errors.As(err, &lexErr))
+}
@@ -1,27 +1,139 @@
 package uniris
 
-import (
-	"fmt"
-)
-
 type parser struct {
-	tokens  []token
-	current int
+	tokens     []token
+	current    int
+	inContract bool
+	functions  int
+	errs       ErrorList
 }
 
+//parse returns the statements of the code or all the syntax errors found in it
 func (p *parser) parse() ([]statement, error) {
 	statements := make([]statement, 0)
+	hasContract := false
 	for !p.isAtEnd() {
+		start := p.current
+		if p.match(TokenContract) {
+			if hasContract {
+				p.errs.add(p.error(p.previous(), "Only one contract can be declared"))
+			}
+			hasContract = true
+			stmt, err := p.contractStatement()
+			if err != nil {
+				p.recover(err, start)
+				continue
+			}
+			statements = append(statements, stmt)
+			continue
+		}
 		stmt, err := p.statement()
 		if err != nil {
-			return nil, err
+			p.recover(err, start)
+			continue
 		}
 		statements = append(statements, stmt)
 	}
+	if len(p.errs) > 0 {
+		return nil, p.errs
+	}
 	return statements, nil
 }
 
+//recover records a syntax error and skips the tokens until the beginning of the next statement.
+//Skipped blocks are balanced so the recovery does not stop inside them.
+func (p *parser) recover(err error, start int) {
+	p.errs.add(err)
+	depth := 0
+	skip := func() {
+		switch p.advance().Type {
+		case TokenLeftBracket:
+			depth++
+		case TokenRightBracket:
+			depth--
+		}
+	}
+	if p.current == start {
+		skip()
+	}
+	for !p.isAtEnd() {
+		if depth <= 0 {
+			switch p.peek().Type {
+			case TokenFunction, TokenFor, TokenIf, TokenPrint, TokenReturn, TokenWhile, TokenContract, TokenRightBracket:
+				return
+			case TokenIdentifier:
+				if p.checkNext(TokenEqual) {
+					return
+				}
+			}
+		}
+		skip()
+	}
+}
+
+func (p *parser) contractStatement() (statement, error) {
+	keyword := p.previous()
+	name, err := p.consume(TokenIdentifier, "Expect contract name")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(TokenLeftBracket, "Expect '{' before contract body"); err != nil {
+		return nil, err
+	}
+
+	stmt := contractStatement{
+		name:      name,
+		state:     make([]assignExpression, 0),
+		functions: make([]funcStatement, 0),
+	}
+	p.inContract = true
+	defer func() { p.inContract = false }()
+
+	members := make(map[string]bool, 0)
+	for !p.check(TokenRightBracket) && !p.isAtEnd() {
+		start := p.current
+		var member token
+		if p.match(TokenFunction) {
+			f, err := p.functionStatement()
+			if err != nil {
+				p.recover(err, start)
+				continue
+			}
+			member = f.(funcStatement).name
+			stmt.functions = append(stmt.functions, f.(funcStatement))
+		} else if p.check(TokenIdentifier) && p.checkNext(TokenEqual) {
+			member = p.advance()
+			p.advance()
+			val, err := p.expression()
+			if err != nil {
+				p.recover(err, start)
+				continue
+			}
+			stmt.state = append(stmt.state, assignExpression{
+				Span: p.spanFrom(member),
+				op:   member,
+				exp:  val,
+			})
+		} else {
+			p.recover(p.error(p.peek(), "Expect state variable or function declaration in contract body"), start)
+			continue
+		}
+		if members[member.Lexeme] {
+			p.errs.add(p.error(member, "Duplicate contract member"))
+		}
+		members[member.Lexeme] = true
+	}
+	if _, err := p.consume(TokenRightBracket, "Expect '}' after contract body"); err != nil {
+		return nil, err
+	}
+	stmt.Span = p.spanFrom(keyword)
+	return stmt, nil
+}
+
 func (p *parser) statement() (statement, error) {
+	if p.check(TokenContract) {
+		return nil, p.error(p.peek(), "Contract must be declared at top level")
+	}
 	if p.match(TokenFunction) {
 		return p.functionStatement()
 	}
@@ -47,16 +159,22 @@ func (p *parser) statement() (statement, error) {
 }
 
 func (p *parser) returnStatement() (statement, error) {
+	keyword := p.previous()
+	if p.functions == 0 {
+		p.errs.add(p.error(keyword, "Cannot return from top level code"))
+	}
 	value, err := p.expression()
 	if err != nil {
 		return nil, err
 	}
 	return returnStatement{
+		Span:  p.spanFrom(keyword),
 		value: value,
 	}, nil
 }
 
 func (p *parser) functionStatement() (statement, error) {
+	keyword := p.previous()
 	name, err := p.consume(TokenIdentifier, "Expect function name")
 	if err != nil {
 		return nil, err
@@ -84,11 +202,14 @@ func (p *parser) functionStatement() (statement, error) {
 		return nil, err
 	}
 
+	p.functions++
 	body, err := p.blockStatements()
+	p.functions--
 	if err != nil {
 		return nil, err
 	}
 	return funcStatement{
+		Span:   p.spanFrom(keyword),
 		body:   body.(blockStmt),
 		name:   name,
 		params: params,
@@ -96,6 +217,10 @@ func (p *parser) functionStatement() (statement, error) {
 }
 
 func (p *parser) forStatement() (statement, error) {
+	keyword := p.previous()
+	if p.check(TokenIdentifier) && p.checkNext(TokenIn) {
+		return p.forInStatement()
+	}
 
 	var init statement
 	if p.check(TokenSemiColon) {
@@ -137,38 +262,38 @@ func (p *parser) forStatement() (statement, error) {
 	if err != nil {
 		return nil, err
 	}
-	if increment != nil {
-		body = blockStmt{
-			statements: []statement{
-				body,
-				expressionStmt{
-					exp: increment,
-				},
-			},
-		}
-	}
+	return forStatement{
+		Span:      p.spanFrom(keyword),
+		init:      init,
+		cond:      cond,
+		increment: increment,
+		body:      body,
+	}, nil
+}
 
-	if cond == nil {
-		cond = literalExpression{
-			value: true,
-		}
-	}
-	body = whileStatement{body: body, cond: cond}
+func (p *parser) forInStatement() (statement, error) {
+	keyword := p.previous()
+	name := p.advance()
+	p.advance()
 
-	if init != nil {
-		body = blockStmt{
-			statements: []statement{
-				init,
-				body,
-			},
-		}
+	collection, err := p.expression()
+	if err != nil {
+		return nil, err
 	}
-
-	return body, nil
-
+	body, err := p.statement()
+	if err != nil {
+		return nil, err
+	}
+	return forInStatement{
+		Span:       p.spanFrom(keyword),
+		name:       name,
+		collection: collection,
+		body:       body,
+	}, nil
 }
 
 func (p *parser) whileStatement() (statement, error) {
+	keyword := p.previous()
 	cond, err := p.expression()
 	if err != nil {
 		return nil, err
@@ -179,12 +304,14 @@ func (p *parser) whileStatement() (statement, error) {
 	}
 
 	return whileStatement{
+		Span: p.spanFrom(keyword),
 		cond: cond,
 		body: body,
 	}, nil
 }
 
 func (p *parser) ifStatement() (statement, error) {
+	keyword := p.previous()
 	cond, err := p.expression()
 	if err != nil {
 		return nil, err
@@ -205,6 +332,7 @@ func (p *parser) ifStatement() (statement, error) {
 	}
 
 	return ifStatement{
+		Span:     p.spanFrom(keyword),
 		cond:     cond,
 		thenStmt: thenStmt,
 		elseStmt: elseStmt,
@@ -212,11 +340,14 @@ func (p *parser) ifStatement() (statement, error) {
 }
 
 func (p *parser) blockStatements() (statement, error) {
+	bracket := p.previous()
 	statements := make([]statement, 0)
 	for !p.check(TokenRightBracket) && !p.isAtEnd() {
+		start := p.current
 		stmt, err := p.statement()
 		if err != nil {
-			return nil, err
+			p.recover(err, start)
+			continue
 		}
 		statements = append(statements, stmt)
 	}
@@ -224,16 +355,18 @@ func (p *parser) blockStatements() (statement, error) {
 		return nil, err
 	}
 	return blockStmt{
+		Span:       p.spanFrom(bracket),
 		statements: statements,
 	}, nil
 }
 
 func (p *parser) printStatement() (statement, error) {
+	keyword := p.previous()
 	val, err := p.expression()
 	if err != nil {
 		return nil, err
 	}
-	return printStmt{exp: val}, nil
+	return printStmt{Span: p.spanFrom(keyword), exp: val}, nil
 }
 
 func (p *parser) expressionStatement() (statement, error) {
@@ -241,7 +374,7 @@ func (p *parser) expressionStatement() (statement, error) {
 	if err != nil {
 		return nil, err
 	}
-	return expressionStmt{exp}, nil
+	return expressionStmt{Span: exp.span(), exp: exp}, nil
 }
 
 func (p *parser) expression() (expression, error) {
@@ -260,10 +393,31 @@ func (p *parser) assignement() (expression, error) {
 			return nil, err
 		}
 
-		return assignExpression{
-			op:  eq,
-			exp: val,
-		}, nil
+		span := join(exp.span(), val.span())
+		switch target := exp.(type) {
+		case variableExpression:
+			return assignExpression{
+				Span: span,
+				op:   target.op,
+				exp:  val,
+			}, nil
+		case getExpression:
+			return setExpression{
+				Span:   span,
+				object: target.object,
+				name:   target.name,
+				value:  val,
+			}, nil
+		case indexExpression:
+			return indexAssignExpression{
+				Span:    span,
+				object:  target.object,
+				bracket: target.bracket,
+				index:   target.index,
+				value:   val,
+			}, nil
+		}
+		return nil, p.error(eq, "Invalid assignment target")
 	}
 
 	return exp, nil
@@ -281,6 +435,7 @@ func (p *parser) or() (expression, error) {
 			return nil, err
 		}
 		exp = logicalExpression{
+			Span:  join(exp.span(), right.span()),
 			left:  exp,
 			right: right,
 			op:    op,
@@ -301,6 +456,7 @@ func (p *parser) and() (expression, error) {
 			return nil, err
 		}
 		exp = logicalExpression{
+			Span:  join(exp.span(), right.span()),
 			op:    op,
 			left:  exp,
 			right: right,
@@ -321,6 +477,7 @@ func (p *parser) equality() (expression, error) {
 			return nil, err
 		}
 		exp = binaryExpression{
+			Span:  join(exp.span(), right.span()),
 			left:  exp,
 			op:    op,
 			right: right,
@@ -342,6 +499,7 @@ func (p *parser) comparison() (expression, error) {
 			return nil, err
 		}
 		exp = binaryExpression{
+			Span:  join(exp.span(), right.span()),
 			left:  exp,
 			op:    op,
 			right: right,
@@ -362,6 +520,7 @@ func (p *parser) addition() (expression, error) {
 			return nil, err
 		}
 		exp = binaryExpression{
+			Span:  join(exp.span(), right.span()),
 			left:  exp,
 			op:    op,
 			right: right,
@@ -375,13 +534,14 @@ func (p *parser) multiplication() (expression, error) {
 	if err != nil {
 		return nil, err
 	}
-	for p.match(TokenSlash, TokenStar) {
+	for p.match(TokenSlash, TokenStar, TokenPercent) {
 		op := p.previous()
 		right, err := p.unary()
 		if err != nil {
 			return nil, err
 		}
 		exp = binaryExpression{
+			Span:  join(exp.span(), right.span()),
 			left:  exp,
 			op:    op,
 			right: right,
@@ -398,6 +558,7 @@ func (p *parser) unary() (expression, error) {
 			return nil, err
 		}
 		return unaryExpression{
+			Span:  join(tokenSpan(op), right.span()),
 			op:    op,
 			right: right,
 		}, nil
@@ -417,6 +578,21 @@ func (p *parser) call() (expression, error) {
 			if err != nil {
 				return nil, err
 			}
+		} else if p.match(TokenLeftSquare) {
+			exp, err = p.finishIndex(exp)
+			if err != nil {
+				return nil, err
+			}
+		} else if p.match(TokenDot) {
+			name, err := p.consume(TokenIdentifier, "Expect property name after '.'")
+			if err != nil {
+				return nil, err
+			}
+			exp = getExpression{
+				Span:   join(exp.span(), tokenSpan(name)),
+				object: exp,
+				name:   name,
+			}
 		} else {
 			break
 		}
@@ -445,21 +621,39 @@ func (p *parser) finishCall(callee expression) (expression, error) {
 		return nil, err
 	}
 	return callExpression{
+		Span:   join(callee.span(), tokenSpan(paren)),
 		args:   args,
 		callee: callee,
 		paren:  paren,
 	}, nil
 }
 
+func (p *parser) finishIndex(object expression) (expression, error) {
+	index, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	bracket, err := p.consume(TokenRightSquare, "Expect ']' after index")
+	if err != nil {
+		return nil, err
+	}
+	return indexExpression{
+		Span:    join(object.span(), tokenSpan(bracket)),
+		object:  object,
+		bracket: bracket,
+		index:   index,
+	}, nil
+}
+
 func (p *parser) primary() (expression, error) {
 	if p.match(TokenFalse) {
-		return literalExpression{value: false}, nil
+		return literalExpression{Span: tokenSpan(p.previous()), value: Bool(false)}, nil
 	}
 	if p.match(TokenTrue) {
-		return literalExpression{value: true}, nil
+		return literalExpression{Span: tokenSpan(p.previous()), value: Bool(true)}, nil
 	}
 	if p.match(TokenNumber, TokenString) {
-		return literalExpression{value: p.previous().Literal}, nil
+		return literalExpression{Span: tokenSpan(p.previous()), value: p.previous().Literal}, nil
 	}
 	if p.match(TokenIdentifier) {
 		op := p.previous()
@@ -469,15 +663,18 @@ func (p *parser) primary() (expression, error) {
 				return nil, err
 			}
 			return assignExpression{
-				op:  op,
-				exp: exp,
+				Span: p.spanFrom(op),
+				op:   op,
+				exp:  exp,
 			}, nil
 		}
 		return variableExpression{
-			op: op,
+			Span: tokenSpan(op),
+			op:   op,
 		}, nil
 	}
 	if p.match(TokenLeftParenthesis) {
+		paren := p.previous()
 		exp, err := p.expression()
 		if err != nil {
 			return nil, err
@@ -485,7 +682,20 @@ func (p *parser) primary() (expression, error) {
 		if _, err := p.consume(TokenRightParenthesis, "Expect ')' after expression"); err != nil {
 			return nil, err
 		}
-		return groupingExpression{exp: exp}, nil
+		return groupingExpression{Span: p.spanFrom(paren), exp: exp}, nil
+	}
+	if p.match(TokenTransaction) {
+		keyword := p.previous()
+		if !p.inContract {
+			return nil, p.error(keyword, "Transaction can only be used inside a contract")
+		}
+		return transactionExpression{Span: tokenSpan(keyword), keyword: keyword}, nil
+	}
+	if p.match(TokenLeftSquare) {
+		return p.list()
+	}
+	if p.match(TokenLeftBracket) {
+		return p.object()
 	}
 
 	err := p.error(p.peek(), "Expected expression")
@@ -493,6 +703,73 @@ func (p *parser) primary() (expression, error) {
 	return nil, err
 }
 
+func (p *parser) list() (expression, error) {
+	bracket := p.previous()
+	elements := make([]expression, 0)
+	if !p.check(TokenRightSquare) {
+		for {
+			exp, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, exp)
+			if !p.match(TokenComma) {
+				break
+			}
+		}
+	}
+	if _, err := p.consume(TokenRightSquare, "Expect ']' after list elements"); err != nil {
+		return nil, err
+	}
+	return listExpression{
+		Span:     p.spanFrom(bracket),
+		elements: elements,
+	}, nil
+}
+
+func (p *parser) object() (expression, error) {
+	bracket := p.previous()
+	keys := make([]string, 0)
+	values := make([]expression, 0)
+	if !p.check(TokenRightBracket) {
+		for {
+			var key string
+			if p.match(TokenIdentifier) {
+				key = p.previous().Lexeme
+			} else if p.match(TokenString) {
+				key = string(p.previous().Literal.(String))
+			} else {
+				return nil, p.error(p.peek(), "Expect object key")
+			}
+			if _, err := p.consume(TokenColon, "Expect ':' after object key"); err != nil {
+				return nil, err
+			}
+			val, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+			values = append(values, val)
+			if !p.match(TokenComma) {
+				break
+			}
+		}
+	}
+	if _, err := p.consume(TokenRightBracket, "Expect '}' after object entries"); err != nil {
+		return nil, err
+	}
+	return objectExpression{
+		Span:   p.spanFrom(bracket),
+		keys:   keys,
+		values: values,
+	}, nil
+}
+
+//spanFrom returns the location going from a token to the last consumed one
+func (p *parser) spanFrom(start token) Span {
+	return join(tokenSpan(start), tokenSpan(p.previous()))
+}
+
 func (p *parser) match(ts ...TokenType) bool {
 	for _, t := range ts {
 		if p.check(t) {
@@ -509,14 +786,20 @@ func (p *parser) consume(t TokenType, message string) (token, error) {
 	}
 
 	err := p.error(p.peek(), message)
+	err.Expected = t
 	return token{}, err
 }
 
-func (p *parser) error(tok token, message string) error {
-	if tok.Type == TokenEndOfFile {
-		return fmt.Errorf("Parsing error at end of line %d - %s", tok.Line, message)
+func (p *parser) error(tok token, message string) *SyntaxError {
+	return &SyntaxError{
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Start:   tok.Start,
+		End:     tok.End,
+		Found:   tok.Type,
+		Lexeme:  tok.Lexeme,
+		Message: message,
 	}
-	return fmt.Errorf("Parsing error at %s of line %d - %s", tok.Lexeme, tok.Line, message)
 }
 
 func (p *parser) check(t TokenType) bool {
@@ -526,6 +809,13 @@ func (p *parser) check(t TokenType) bool {
 	return p.peek().Type == t
 }
 
+func (p *parser) checkNext(t TokenType) bool {
+	if p.isAtEnd() || p.current+1 >= len(p.tokens) {
+		return false
+	}
+	return p.tokens[p.current+1].Type == t
+}
+
 func (p *parser) advance() token {
 	if !p.isAtEnd() {
 		p.current++
@@ -542,5 +832,9 @@ func (p *parser) peek() token {
 }
 
 func (p *parser) previous() token {
+	//Statements can be parsed without their keyword, the span then starts at the beginning
+	if p.current == 0 {
+		return token{}
+	}
 	return p.tokens[p.current-1]
 }
@@ -1,6 +1,7 @@
 package uniris
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -84,8 +85,8 @@ func TestParserCheck(t *testing.T) {
 
 func TestParserError(t *testing.T) {
 	p := parser{}
-	assert.Error(t, p.error(token{Type: TokenEndOfFile, Line: 1}, "Invalid"), "Parsing error at end of line 1 - Invalid")
-	assert.Error(t, p.error(token{Type: TokenNumber, Line: 1, Lexeme: "2"}, "Invalid"), "Parsing error at 2 of line 1 - Invalid")
+	assert.EqualError(t, p.error(token{Type: TokenEndOfFile, Line: 1, Column: 4}, "Invalid"), "Parsing error at end of line 1, column 4 - Invalid")
+	assert.EqualError(t, p.error(token{Type: TokenNumber, Line: 1, Column: 2, Lexeme: "2"}, "Invalid"), "Parsing error at 2 of line 1, column 2 - Invalid")
 }
 
 func TestParserConsume(t *testing.T) {
@@ -136,26 +137,26 @@ func TestParserPrimaryLiteralExpression(t *testing.T) {
 		tokens: []token{
 			token{Type: TokenFalse},
 			token{Type: TokenTrue},
-			token{Type: TokenNumber, Literal: "10"},
-			token{Type: TokenString, Literal: "hello"},
+			token{Type: TokenNumber, Literal: String("10")},
+			token{Type: TokenString, Literal: String("hello")},
 		},
 	}
 
 	exp, err := p.primary()
 	assert.Nil(t, err)
-	assert.Equal(t, literalExpression{value: false}, exp)
+	assert.Equal(t, literalExpression{value: Bool(false)}, exp)
 
 	exp, err = p.primary()
 	assert.Nil(t, err)
-	assert.Equal(t, literalExpression{value: true}, exp)
+	assert.Equal(t, literalExpression{value: Bool(true)}, exp)
 
 	exp, err = p.primary()
 	assert.Nil(t, err)
-	assert.Equal(t, literalExpression{value: "10"}, exp)
+	assert.Equal(t, literalExpression{value: String("10")}, exp)
 
 	exp, err = p.primary()
 	assert.Nil(t, err)
-	assert.Equal(t, literalExpression{value: "hello"}, exp)
+	assert.Equal(t, literalExpression{value: String("hello")}, exp)
 }
 
 func TestParserPrimaryAssignExpression(t *testing.T) {
@@ -163,7 +164,7 @@ func TestParserPrimaryAssignExpression(t *testing.T) {
 		tokens: []token{
 			token{Type: TokenIdentifier},
 			token{Type: TokenEqual},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenEndOfFile},
 		},
 	}
@@ -175,7 +176,7 @@ func TestParserPrimaryAssignExpression(t *testing.T) {
 			Type: TokenIdentifier,
 		},
 		exp: literalExpression{
-			value: 10,
+			value: Int(10),
 		},
 	}, exp)
 }
@@ -211,7 +212,7 @@ func TestParserPrimaryGroupingExpression(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, groupingExpression{
 		exp: literalExpression{
-			value: true,
+			value: Bool(true),
 		},
 	}, exp)
 }
@@ -219,7 +220,7 @@ func TestParserPrimaryGroupingExpression(t *testing.T) {
 func TestParserFinishCall(t *testing.T) {
 	p := parser{
 		tokens: []token{
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenRightParenthesis},
 			token{Type: TokenEndOfFile},
 		},
@@ -229,7 +230,7 @@ func TestParserFinishCall(t *testing.T) {
 	assert.Equal(t, callExpression{
 		args: []expression{
 			literalExpression{
-				value: 10,
+				value: Int(10),
 			},
 		},
 		paren: token{
@@ -266,7 +267,7 @@ func TestParserUnary(t *testing.T) {
 			token{Type: TokenBang},
 			token{Type: TokenTrue},
 			token{Type: TokenMinus},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenEndOfFile},
 		},
 	}
@@ -276,7 +277,7 @@ func TestParserUnary(t *testing.T) {
 	assert.Equal(t, unaryExpression{
 		op: token{Type: TokenBang},
 		right: literalExpression{
-			value: true,
+			value: Bool(true),
 		},
 	}, exp)
 
@@ -285,7 +286,7 @@ func TestParserUnary(t *testing.T) {
 	assert.Equal(t, unaryExpression{
 		op: token{Type: TokenMinus},
 		right: literalExpression{
-			value: 10,
+			value: Int(10),
 		},
 	}, exp)
 }
@@ -293,9 +294,9 @@ func TestParserUnary(t *testing.T) {
 func TestParserMultiplication(t *testing.T) {
 	p := parser{
 		tokens: []token{
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenStar},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenEndOfFile},
 		},
 	}
@@ -303,21 +304,21 @@ func TestParserMultiplication(t *testing.T) {
 	exp, err := p.multiplication()
 	assert.Nil(t, err)
 	assert.Equal(t, binaryExpression{
-		left:  literalExpression{value: 10},
+		left:  literalExpression{value: Int(10)},
 		op:    token{Type: TokenStar},
-		right: literalExpression{value: 10},
+		right: literalExpression{value: Int(10)},
 	}, exp)
 }
 
 func TestParserAddition(t *testing.T) {
 	p := parser{
 		tokens: []token{
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenPlus},
-			token{Type: TokenNumber, Literal: 10},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenMinus},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenEndOfFile},
 		},
 	}
@@ -325,35 +326,35 @@ func TestParserAddition(t *testing.T) {
 	exp, err := p.addition()
 	assert.Nil(t, err)
 	assert.Equal(t, binaryExpression{
-		left:  literalExpression{value: 10},
+		left:  literalExpression{value: Int(10)},
 		op:    token{Type: TokenPlus},
-		right: literalExpression{value: 10},
+		right: literalExpression{value: Int(10)},
 	}, exp)
 
 	exp, err = p.addition()
 	assert.Nil(t, err)
 	assert.Equal(t, binaryExpression{
-		left:  literalExpression{value: 10},
+		left:  literalExpression{value: Int(10)},
 		op:    token{Type: TokenMinus},
-		right: literalExpression{value: 10},
+		right: literalExpression{value: Int(10)},
 	}, exp)
 }
 
 func TestParserComparison(t *testing.T) {
 	p := parser{
 		tokens: []token{
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenGreater},
-			token{Type: TokenNumber, Literal: 10},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenGreaterEqual},
-			token{Type: TokenNumber, Literal: 10},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenLess},
-			token{Type: TokenNumber, Literal: 10},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenLessEqual},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenEndOfFile},
 		},
 	}
@@ -361,45 +362,45 @@ func TestParserComparison(t *testing.T) {
 	exp, err := p.comparison()
 	assert.Nil(t, err)
 	assert.Equal(t, binaryExpression{
-		left:  literalExpression{value: 10},
+		left:  literalExpression{value: Int(10)},
 		op:    token{Type: TokenGreater},
-		right: literalExpression{value: 10},
+		right: literalExpression{value: Int(10)},
 	}, exp)
 
 	exp, err = p.comparison()
 	assert.Nil(t, err)
 	assert.Equal(t, binaryExpression{
-		left:  literalExpression{value: 10},
+		left:  literalExpression{value: Int(10)},
 		op:    token{Type: TokenGreaterEqual},
-		right: literalExpression{value: 10},
+		right: literalExpression{value: Int(10)},
 	}, exp)
 
 	exp, err = p.comparison()
 	assert.Nil(t, err)
 	assert.Equal(t, binaryExpression{
-		left:  literalExpression{value: 10},
+		left:  literalExpression{value: Int(10)},
 		op:    token{Type: TokenLess},
-		right: literalExpression{value: 10},
+		right: literalExpression{value: Int(10)},
 	}, exp)
 
 	exp, err = p.comparison()
 	assert.Nil(t, err)
 	assert.Equal(t, binaryExpression{
-		left:  literalExpression{value: 10},
+		left:  literalExpression{value: Int(10)},
 		op:    token{Type: TokenLessEqual},
-		right: literalExpression{value: 10},
+		right: literalExpression{value: Int(10)},
 	}, exp)
 }
 
 func TestParserEquality(t *testing.T) {
 	p := parser{
 		tokens: []token{
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenEqualEqual},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenIdentifier},
 			token{Type: TokenBangEqual},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenEndOfFile},
 		},
 	}
@@ -407,9 +408,9 @@ func TestParserEquality(t *testing.T) {
 	exp, err := p.equality()
 	assert.Nil(t, err)
 	assert.Equal(t, binaryExpression{
-		left:  literalExpression{value: 10},
+		left:  literalExpression{value: Int(10)},
 		op:    token{Type: TokenEqualEqual},
-		right: literalExpression{value: 10},
+		right: literalExpression{value: Int(10)},
 	}, exp)
 
 	exp, err = p.equality()
@@ -417,7 +418,7 @@ func TestParserEquality(t *testing.T) {
 	assert.Equal(t, binaryExpression{
 		left:  variableExpression{op: token{Type: TokenIdentifier}},
 		op:    token{Type: TokenBangEqual},
-		right: literalExpression{value: 10},
+		right: literalExpression{value: Int(10)},
 	}, exp)
 }
 
@@ -434,9 +435,9 @@ func TestParserAnd(t *testing.T) {
 	exp, err := p.and()
 	assert.Nil(t, err)
 	assert.Equal(t, logicalExpression{
-		left:  literalExpression{value: true},
+		left:  literalExpression{value: Bool(true)},
 		op:    token{Type: TokenAnd},
-		right: literalExpression{value: true},
+		right: literalExpression{value: Bool(true)},
 	}, exp)
 }
 
@@ -453,9 +454,9 @@ func TestParserOr(t *testing.T) {
 	exp, err := p.or()
 	assert.Nil(t, err)
 	assert.Equal(t, logicalExpression{
-		left:  literalExpression{value: true},
+		left:  literalExpression{value: Bool(true)},
 		op:    token{Type: TokenOr},
-		right: literalExpression{value: true},
+		right: literalExpression{value: Bool(true)},
 	}, exp)
 }
 
@@ -464,7 +465,7 @@ func TestParserAssignement(t *testing.T) {
 		tokens: []token{
 			token{Type: TokenIdentifier},
 			token{Type: TokenEqual},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenEndOfFile},
 		},
 	}
@@ -472,7 +473,7 @@ func TestParserAssignement(t *testing.T) {
 	exp, err := p.assignement()
 	assert.Nil(t, err)
 	assert.Equal(t, assignExpression{
-		exp: literalExpression{value: 10},
+		exp: literalExpression{value: Int(10)},
 		op:  token{Type: TokenIdentifier},
 	}, exp)
 }
@@ -480,7 +481,7 @@ func TestParserAssignement(t *testing.T) {
 func TestParserExpressionStatement(t *testing.T) {
 	p := parser{
 		tokens: []token{
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenEndOfFile},
 		},
 	}
@@ -488,7 +489,7 @@ func TestParserExpressionStatement(t *testing.T) {
 	stmt, err := p.expressionStatement()
 	assert.Nil(t, err)
 	assert.Equal(t, expressionStmt{
-		exp: literalExpression{value: 10},
+		exp: literalExpression{value: Int(10)},
 	}, stmt)
 
 }
@@ -496,7 +497,7 @@ func TestParserExpressionStatement(t *testing.T) {
 func TestParserPrintStatement(t *testing.T) {
 	p := parser{
 		tokens: []token{
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenEndOfFile},
 		},
 	}
@@ -504,7 +505,7 @@ func TestParserPrintStatement(t *testing.T) {
 	stmt, err := p.printStatement()
 	assert.Nil(t, err)
 	assert.Equal(t, printStmt{
-		exp: literalExpression{value: 10},
+		exp: literalExpression{value: Int(10)},
 	}, stmt)
 }
 
@@ -512,7 +513,7 @@ func TestParserBlockStatement(t *testing.T) {
 	p := parser{
 		tokens: []token{
 			token{Type: TokenPrint},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenRightBracket},
 			token{Type: TokenEndOfFile},
 		},
@@ -523,7 +524,7 @@ func TestParserBlockStatement(t *testing.T) {
 	assert.Equal(t, blockStmt{
 		statements: []statement{
 			printStmt{
-				exp: literalExpression{value: 10},
+				exp: literalExpression{value: Int(10)},
 			},
 		},
 	}, stmt)
@@ -543,7 +544,7 @@ func TestParserIfStatement(t *testing.T) {
 	stmt, err := p.ifStatement()
 	assert.Nil(t, err)
 	assert.Equal(t, ifStatement{
-		cond:     literalExpression{value: true},
+		cond:     literalExpression{value: Bool(true)},
 		thenStmt: expressionStmt{exp: literalExpression{}},
 		elseStmt: expressionStmt{exp: literalExpression{}},
 	}, stmt)
@@ -561,7 +562,7 @@ func TestParserWhileStatement(t *testing.T) {
 	stmt, err := p.whileStatement()
 	assert.Nil(t, err)
 	assert.Equal(t, whileStatement{
-		cond: literalExpression{value: true},
+		cond: literalExpression{value: Bool(true)},
 		body: expressionStmt{exp: literalExpression{}},
 	}, stmt)
 }
@@ -571,17 +572,17 @@ func TestParserForStatement(t *testing.T) {
 		tokens: []token{
 			token{Type: TokenIdentifier, Lexeme: "i"},
 			token{Type: TokenEqual},
-			token{Type: TokenNumber, Literal: 0},
+			token{Type: TokenNumber, Literal: Int(0)},
 			token{Type: TokenSemiColon},
 			token{Type: TokenIdentifier, Lexeme: "i"},
 			token{Type: TokenLess},
-			token{Type: TokenNumber, Literal: 10},
+			token{Type: TokenNumber, Literal: Int(10)},
 			token{Type: TokenSemiColon},
 			token{Type: TokenIdentifier, Lexeme: "i"},
 			token{Type: TokenEqual},
 			token{Type: TokenIdentifier, Lexeme: "i"},
 			token{Type: TokenPlus},
-			token{Type: TokenNumber, Literal: 1},
+			token{Type: TokenNumber, Literal: Int(1)},
 			token{Type: TokenPrint},
 			token{Type: TokenIdentifier, Lexeme: "i"},
 			token{Type: TokenEndOfFile},
@@ -590,11 +591,12 @@ func TestParserForStatement(t *testing.T) {
 
 	stmt, err := p.forStatement()
 	assert.Nil(t, err)
+	assert.IsType(t, forStatement{}, stmt)
 	assert.Equal(t, blockStmt{
 		statements: []statement{
 			assignExpression{
 				op:  token{Type: TokenIdentifier, Lexeme: "i"},
-				exp: literalExpression{value: 0},
+				exp: literalExpression{value: Int(0)},
 			},
 			whileStatement{
 				body: blockStmt{
@@ -602,7 +604,12 @@ func TestParserForStatement(t *testing.T) {
 						printStmt{exp: variableExpression{op: token{Type: TokenIdentifier, Lexeme: "i"}}},
 						expressionStmt{
 							exp: assignExpression{
-								exp: literalExpression{},
+								op: token{Type: TokenIdentifier, Lexeme: "i"},
+								exp: binaryExpression{
+									left:  variableExpression{op: token{Type: TokenIdentifier, Lexeme: "i"}},
+									op:    token{Type: TokenPlus},
+									right: literalExpression{value: Int(1)},
+								},
 							},
 						},
 					},
@@ -610,9 +617,244 @@ func TestParserForStatement(t *testing.T) {
 				cond: binaryExpression{
 					left:  variableExpression{op: token{Type: TokenIdentifier, Lexeme: "i"}},
 					op:    token{Type: TokenLess},
-					right: literalExpression{value: 10},
+					right: literalExpression{value: Int(10)},
 				},
 			},
 		},
+	}, stmt.(forStatement).desugar())
+}
+
+func TestParserPrimaryListExpression(t *testing.T) {
+	p := parser{
+		tokens: []token{
+			token{Type: TokenLeftSquare},
+			token{Type: TokenNumber, Literal: Int(1)},
+			token{Type: TokenComma},
+			token{Type: TokenString, Literal: String("a")},
+			token{Type: TokenRightSquare},
+			token{Type: TokenEndOfFile},
+		},
+	}
+
+	exp, err := p.primary()
+	assert.Nil(t, err)
+	assert.Equal(t, listExpression{
+		elements: []expression{
+			literalExpression{value: Int(1)},
+			literalExpression{value: String("a")},
+		},
+	}, exp)
+}
+
+func TestParserIndexAssignement(t *testing.T) {
+	p := parser{
+		tokens: []token{
+			token{Type: TokenIdentifier, Lexeme: "xs"},
+			token{Type: TokenLeftSquare},
+			token{Type: TokenNumber, Literal: Int(0)},
+			token{Type: TokenRightSquare},
+			token{Type: TokenEqual},
+			token{Type: TokenNumber, Literal: Int(10)},
+			token{Type: TokenEndOfFile},
+		},
+	}
+
+	exp, err := p.assignement()
+	assert.Nil(t, err)
+	assert.Equal(t, indexAssignExpression{
+		object:  variableExpression{op: token{Type: TokenIdentifier, Lexeme: "xs"}},
+		bracket: token{Type: TokenRightSquare},
+		index:   literalExpression{value: Int(0)},
+		value:   literalExpression{value: Int(10)},
+	}, exp)
+}
+
+func TestParserInvalidAssignementTarget(t *testing.T) {
+	p := parser{
+		tokens: []token{
+			token{Type: TokenNumber, Literal: Int(1)},
+			token{Type: TokenEqual, Lexeme: "=", Line: 1},
+			token{Type: TokenNumber, Literal: Int(10)},
+			token{Type: TokenEndOfFile},
+		},
+	}
+
+	_, err := p.assignement()
+	assert.EqualError(t, err, "Parsing error at = of line 1, column 0 - Invalid assignment target")
+}
+
+func TestParserPrimaryObjectExpression(t *testing.T) {
+	p := parser{
+		tokens: []token{
+			token{Type: TokenLeftBracket},
+			token{Type: TokenIdentifier, Lexeme: "a"},
+			token{Type: TokenColon},
+			token{Type: TokenNumber, Literal: Int(1)},
+			token{Type: TokenComma},
+			token{Type: TokenString, Literal: String("b c")},
+			token{Type: TokenColon},
+			token{Type: TokenTrue},
+			token{Type: TokenRightBracket},
+			token{Type: TokenEndOfFile},
+		},
+	}
+
+	exp, err := p.primary()
+	assert.Nil(t, err)
+	assert.Equal(t, objectExpression{
+		keys:   []string{"a", "b c"},
+		values: []expression{literalExpression{value: Int(1)}, literalExpression{value: Bool(true)}},
+	}, exp)
+}
+
+func TestParserSetExpression(t *testing.T) {
+	p := parser{
+		tokens: []token{
+			token{Type: TokenIdentifier, Lexeme: "o"},
+			token{Type: TokenDot},
+			token{Type: TokenIdentifier, Lexeme: "a"},
+			token{Type: TokenEqual},
+			token{Type: TokenNumber, Literal: Int(10)},
+			token{Type: TokenEndOfFile},
+		},
+	}
+
+	exp, err := p.assignement()
+	assert.Nil(t, err)
+	assert.Equal(t, setExpression{
+		object: variableExpression{op: token{Type: TokenIdentifier, Lexeme: "o"}},
+		name:   token{Type: TokenIdentifier, Lexeme: "a"},
+		value:  literalExpression{value: Int(10)},
+	}, exp)
+}
+
+func TestParserForInStatement(t *testing.T) {
+	p := parser{
+		tokens: []token{
+			token{Type: TokenIdentifier, Lexeme: "k"},
+			token{Type: TokenIn},
+			token{Type: TokenIdentifier, Lexeme: "o"},
+			token{Type: TokenPrint},
+			token{Type: TokenIdentifier, Lexeme: "k"},
+			token{Type: TokenEndOfFile},
+		},
+	}
+
+	stmt, err := p.forStatement()
+	assert.Nil(t, err)
+	assert.Equal(t, forInStatement{
+		name:       token{Type: TokenIdentifier, Lexeme: "k"},
+		collection: variableExpression{op: token{Type: TokenIdentifier, Lexeme: "o"}},
+		body:       printStmt{exp: variableExpression{op: token{Type: TokenIdentifier, Lexeme: "k"}}},
 	}, stmt)
 }
+
+func TestParserContractStatement(t *testing.T) {
+	p := newTestParser(`contract Wallet {
+			balance = 0
+			function deposit(amount) {
+				balance = balance + amount
+			}
+		}`)
+
+	stmts, err := p.parse()
+	assert.Nil(t, err)
+	assert.Len(t, stmts, 1)
+	stmt := stmts[0].(contractStatement)
+	assert.Equal(t, "Wallet", stmt.name.Lexeme)
+	assert.Len(t, stmt.state, 1)
+	assert.Equal(t, "balance", stmt.state[0].op.Lexeme)
+	assert.Len(t, stmt.functions, 1)
+	assert.Equal(t, "deposit", stmt.functions[0].name.Lexeme)
+}
+
+func TestParserContractStatementErrors(t *testing.T) {
+	p := newTestParser("contract A {}\ncontract B {}")
+	_, err := p.parse()
+	assert.EqualError(t, err, "Parsing error at contract of line 2, column 1 - Only one contract can be declared")
+
+	p = newTestParser("contract A {\nprint 1\n}")
+	_, err = p.parse()
+	assert.EqualError(t, err, "Parsing error at print of line 2, column 1 - Expect state variable or function declaration in contract body")
+
+	p = newTestParser("contract A {\na = 1\na = 2\n}")
+	_, err = p.parse()
+	assert.EqualError(t, err, "Parsing error at a of line 3, column 1 - Duplicate contract member")
+
+	p = newTestParser("if true {\ncontract A {}\n}")
+	_, err = p.parse()
+	assert.EqualError(t, err, "Parsing error at contract of line 2, column 1 - Contract must be declared at top level")
+}
+
+func newTestParser(code string) parser {
+	sc := newScanner(code)
+	return parser{
+		tokens: sc.scanTokens(),
+	}
+}
+
+func TestParserTransactionOutsideContract(t *testing.T) {
+	p := newTestParser("print transaction.amount")
+	_, err := p.parse()
+	assert.EqualError(t, err, "Parsing error at transaction of line 1, column 7 - Transaction can only be used inside a contract")
+}
+
+func TestParserReportsAllErrors(t *testing.T) {
+	p := newTestParser(`a = (1 + 2
+print 1 +
+function f( {
+    x = 1
+}
+if a {
+    b = * 2
+    c = 3
+}
+d = 4
+`)
+	_, err := p.parse()
+	assert.EqualError(t, err, `Parsing error at print of line 2, column 1 - Expect ')' after expression
+Parsing error at function of line 3, column 1 - Expected expression
+Parsing error at { of line 3, column 13 - Expect parameter name
+Parsing error at * of line 7, column 9 - Expected expression`)
+}
+
+func TestParserReportsEachErrorOnce(t *testing.T) {
+	//The recovery stops on the } which caused the error, the next statement fails on it again
+	p := newTestParser("a = }\nb = 1")
+	_, err := p.parse()
+	assert.EqualError(t, err, "Parsing error at } of line 1, column 5 - Expected expression")
+
+	var errs ErrorList
+	errs.add(p.error(token{Line: 1, Column: 1, Lexeme: "x"}, "Expected expression"))
+	errs.add(p.error(token{Line: 1, Column: 1, Lexeme: "x"}, "Expected expression"))
+	errs.add(p.error(token{Line: 2, Column: 1, Lexeme: "x"}, "Expected expression"))
+	assert.Len(t, errs, 2)
+}
+
+func TestParserSyntaxErrorDetails(t *testing.T) {
+	p := newTestParser("f(1, 2")
+	_, err := p.parse()
+
+	var syntaxErr *SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, 1, syntaxErr.Line)
+	assert.Equal(t, 7, syntaxErr.Column)
+	assert.Equal(t, TokenRightParenthesis, syntaxErr.Expected)
+	assert.Equal(t, TokenEndOfFile, syntaxErr.Found)
+}
+
+func TestParserSpans(t *testing.T) {
+	p := newTestParser("x = 1\nif x >= 10 {\n  print f(x, \"é\")\n}")
+	stmts, err := p.parse()
+	assert.Nil(t, err)
+
+	assert.Equal(t, Span{Line: 1, Column: 1, Start: 0, End: 5}, stmts[0].span())
+
+	ifStmt := stmts[1].(ifStatement)
+	assert.Equal(t, Span{Line: 2, Column: 1, Start: 6, End: 39}, ifStmt.span())
+	assert.Equal(t, Span{Line: 2, Column: 4, Start: 9, End: 16}, ifStmt.cond.span())
+
+	print := ifStmt.thenStmt.(blockStmt).statements[0].(printStmt)
+	assert.Equal(t, Span{Line: 3, Column: 3, Start: 21, End: 37}, print.span())
+	assert.Equal(t, Span{Line: 3, Column: 9, Start: 27, End: 37}, print.exp.span())
+}
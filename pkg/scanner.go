@@ -3,13 +3,19 @@ package uniris
 import (
 	"fmt"
 	"strconv"
+	"unicode/utf8"
 )
 
 type token struct {
 	Type    TokenType
 	Lexeme  string
-	Literal interface{}
+	Literal Value
 	Line    int
+	Column  int
+
+	//Start and End are the byte offsets of the token in the source, End excluded
+	Start int
+	End   int
 }
 
 type TokenType string
@@ -28,6 +34,7 @@ var keywords = map[string]TokenType{
 	"return":      TokenReturn,
 	"transaction": TokenTransaction,
 	"contract":    TokenContract,
+	"in":          TokenIn,
 }
 
 const (
@@ -36,12 +43,16 @@ const (
 	TokenRightParenthesis TokenType = "RIGHT_PARENTHESIS"
 	TokenLeftBracket      TokenType = "LEFT_BRACKET"
 	TokenRightBracket     TokenType = "RIGHT_BRACKET"
+	TokenLeftSquare       TokenType = "LEFT_SQUARE"
+	TokenRightSquare      TokenType = "RIGHT_SQUARE"
 	TokenPlus             TokenType = "PLUS"
 	TokenMinus            TokenType = "MINUS"
 	TokenStar             TokenType = "STAR"
 	TokenSlash            TokenType = "SLASH"
+	TokenPercent          TokenType = "PERCENT"
 	TokenDot              TokenType = "DOT"
 	TokenComma            TokenType = "COMMA"
+	TokenColon            TokenType = "COLON"
 	TokenSemiColon        TokenType = "SEMICOLON"
 
 	//One or two character tokens
@@ -74,32 +85,52 @@ const (
 	TokenReturn      TokenType = "RETURN"
 	TokenTransaction TokenType = "TRANSACTION"
 	TokenContract    TokenType = "CONTRACT"
+	TokenIn          TokenType = "IN"
 )
 
 type scanner struct {
-	source  []rune
-	start   int
-	current int
-	line    int
-	tokens  []token
+	source      []rune
+	widths      []int
+	start       int
+	startOffset int
+	current     int
+	line        int
+	lineStart   int
+	offset      int
+	tokens      []token
+	errs        []error
 }
 
 func newScanner(code string) scanner {
-	return scanner{
-		source:  []rune(code),
+	sc := scanner{
 		start:   0,
 		current: 0,
 		line:    1,
 	}
+	//The width of each rune in the code, an invalid byte gives a one byte U+FFFD
+	for len(code) > 0 {
+		r, width := utf8.DecodeRuneInString(code)
+		sc.source = append(sc.source, r)
+		sc.widths = append(sc.widths, width)
+		code = code[width:]
+	}
+	return sc
 }
 
 func (sc *scanner) scanTokens() []token {
 	for !sc.isAtEnd() {
 		sc.start = sc.current
+		sc.startOffset = sc.offset
 		sc.scanToken()
 	}
 
-	sc.tokens = append(sc.tokens, token{Type: TokenEndOfFile, Line: sc.line})
+	sc.tokens = append(sc.tokens, token{
+		Type:   TokenEndOfFile,
+		Line:   sc.line,
+		Column: sc.current - sc.lineStart + 1,
+		Start:  sc.offset,
+		End:    sc.offset,
+	})
 	return sc.tokens
 }
 
@@ -122,6 +153,12 @@ func (sc *scanner) scanToken() {
 	case '}':
 		sc.addEmptyToken(TokenRightBracket)
 		break
+	case '[':
+		sc.addEmptyToken(TokenLeftSquare)
+		break
+	case ']':
+		sc.addEmptyToken(TokenRightSquare)
+		break
 	case '+':
 		sc.addEmptyToken(TokenPlus)
 		break
@@ -131,12 +168,18 @@ func (sc *scanner) scanToken() {
 	case '*':
 		sc.addEmptyToken(TokenStar)
 		break
+	case '%':
+		sc.addEmptyToken(TokenPercent)
+		break
 	case '.':
 		sc.addEmptyToken(TokenDot)
 		break
 	case ',':
 		sc.addEmptyToken(TokenComma)
 		break
+	case ':':
+		sc.addEmptyToken(TokenColon)
+		break
 	case ';':
 		sc.addEmptyToken(TokenSemiColon)
 		break
@@ -185,6 +228,7 @@ func (sc *scanner) scanToken() {
 		break
 	case '\n':
 		sc.line++
+		sc.lineStart = sc.current
 		break
 	case '"':
 		sc.string()
@@ -195,7 +239,7 @@ func (sc *scanner) scanToken() {
 		} else if sc.isAlpha(c) {
 			sc.identifier()
 		} else {
-			panic(fmt.Errorf("Error: Line: %d, Unexpected character: %s", sc.line, string(c)))
+			sc.error(fmt.Sprintf("Unexpected character %s", string(c)))
 		}
 		break
 	}
@@ -233,8 +277,12 @@ func (sc *scanner) number() {
 		sc.advance()
 	}
 
+	integer := true
+
 	// Look for a fractional part.
 	if sc.peek() == '.' && sc.isDigit(sc.peekNext()) {
+		integer = false
+
 		// Consume the "."
 		sc.advance()
 
@@ -243,9 +291,31 @@ func (sc *scanner) number() {
 		}
 	}
 
+	// Look for the decimal suffix.
+	if sc.peek() == 'd' && !sc.isAlphaNumeric(sc.peekNext()) {
+		d, err := ParseDecimal(string(sc.source[sc.start:sc.current]))
+		sc.advance()
+		if err != nil {
+			sc.error(err.Error())
+			return
+		}
+		sc.addToken(TokenNumber, d)
+		return
+	}
+
+	if integer {
+		i, err := strconv.ParseInt(string(sc.source[sc.start:sc.current]), 10, 64)
+		if err != nil {
+			sc.error(fmt.Sprintf("Integer %s overflows", string(sc.source[sc.start:sc.current])))
+			return
+		}
+		sc.addToken(TokenNumber, Int(i))
+		return
+	}
+
 	float, err := strconv.ParseFloat(string(sc.source[sc.start:sc.current]), 64)
 	if err == nil {
-		sc.addToken(TokenNumber, float)
+		sc.addToken(TokenNumber, Float(float))
 	}
 }
 
@@ -270,17 +340,20 @@ func (sc *scanner) match(c rune) bool {
 	if sc.source[sc.current] != c {
 		return false
 	}
-	sc.current++
+	sc.advance()
 	return true
 }
 
-func (sc *scanner) addToken(t TokenType, lit interface{}) {
+func (sc *scanner) addToken(t TokenType, lit Value) {
 	text := sc.source[sc.start:sc.current]
 	sc.tokens = append(sc.tokens, token{
 		Type:    t,
 		Lexeme:  string(text),
 		Literal: lit,
 		Line:    sc.line,
+		Column:  sc.start - sc.lineStart + 1,
+		Start:   sc.startOffset,
+		End:     sc.offset,
 	})
 }
 
@@ -288,17 +361,33 @@ func (sc *scanner) addEmptyToken(t TokenType) {
 	sc.addToken(t, nil)
 }
 
+//error records a lexical error positioned at the start of the current token and lets the scanning go on
+func (sc *scanner) error(message string) {
+	sc.errs = append(sc.errs, &LexicalError{
+		Line:    sc.line,
+		Column:  sc.start - sc.lineStart + 1,
+		Start:   sc.startOffset,
+		End:     sc.offset,
+		Message: message,
+	})
+}
+
+//advance consumes a rune, offset follows its width in the code
 func (sc *scanner) advance() rune {
 	c := sc.source[sc.current]
+	sc.offset += sc.widths[sc.current]
 	sc.current++
 	return c
 }
 
 func (sc *scanner) string() {
 
+	//The token is positioned at its opening quote, lines are counted once it is added
+	line, lineStart := sc.line, sc.lineStart
 	for sc.peek() != '"' && !sc.isAtEnd() {
 		if sc.peek() == '\n' {
-			sc.line++
+			line++
+			lineStart = sc.current + 1
 		}
 		sc.advance()
 
@@ -306,7 +395,9 @@ func (sc *scanner) string() {
 
 	// Unterminated string.
 	if sc.isAtEnd() {
-		panic(fmt.Sprintf("ERROR: Line: %d, Unterminated string.", sc.line))
+		sc.error("Unterminated string")
+		sc.line, sc.lineStart = line, lineStart
+		return
 	}
 
 	// The closing ".
@@ -314,5 +405,6 @@ func (sc *scanner) string() {
 
 	// Trim the surrounding quotes.
 	value := sc.source[sc.start+1 : sc.current-1]
-	sc.addToken(TokenString, string(value))
+	sc.addToken(TokenString, String(value))
+	sc.line, sc.lineStart = line, lineStart
 }
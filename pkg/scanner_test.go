@@ -69,12 +69,14 @@ func TestScanString(t *testing.T) {
 	s.advance()
 	s.string()
 	assert.Equal(t, TokenString, s.tokens[0].Type)
-	assert.Equal(t, "hello", s.tokens[0].Literal)
+	assert.Equal(t, String("hello"), s.tokens[0].Literal)
 	assert.Equal(t, "\"hello\"", s.tokens[0].Lexeme)
 
 	s = newScanner("\"hello")
 	s.advance()
-	assert.Panics(t, s.string)
+	s.string()
+	assert.Len(t, s.tokens, 0)
+	assert.EqualError(t, s.errs[0], "Lexical error at line 1, column 1 - Unterminated string")
 
 	s = newScanner("\"hello\nWorld\"")
 	s.advance()
@@ -87,7 +89,22 @@ func TestScanNumber(t *testing.T) {
 	s.number()
 	assert.Equal(t, "123", s.tokens[0].Lexeme)
 	assert.Equal(t, TokenNumber, s.tokens[0].Type)
-	assert.Equal(t, float64(123), s.tokens[0].Literal)
+	assert.Equal(t, Int(123), s.tokens[0].Literal)
+
+	s = newScanner("1.5")
+	s.number()
+	assert.Equal(t, Float(1.5), s.tokens[0].Literal)
+
+	s = newScanner("99999999999999999999")
+	s.number()
+	assert.Len(t, s.tokens, 0)
+	assert.EqualError(t, s.errs[0], "Lexical error at line 1, column 1 - Integer 99999999999999999999 overflows")
+}
+
+func TestScanPercent(t *testing.T) {
+	s := newScanner("%")
+	s.scanToken()
+	assert.Equal(t, TokenPercent, s.tokens[0].Type)
 }
 
 func TestScanTokenParenthesis(t *testing.T) {
@@ -262,7 +279,19 @@ func TestScanTokenBreakline(t *testing.T) {
 
 func TestScanTokenUnexpected(t *testing.T) {
 	s := newScanner("°")
-	assert.Panics(t, s.scanToken)
+	s.scanToken()
+	assert.Len(t, s.tokens, 0)
+	assert.EqualError(t, s.errs[0], "Lexical error at line 1, column 1 - Unexpected character °")
+}
+
+func TestScanCollectsErrors(t *testing.T) {
+	s := newScanner("a = 1 @\nb = 0.0000000000000000001d\nc = \"open")
+	tokens := s.scanTokens()
+	assert.Equal(t, TokenEndOfFile, tokens[len(tokens)-1].Type)
+	assert.Len(t, s.errs, 3)
+	assert.EqualError(t, s.errs[0], "Lexical error at line 1, column 7 - Unexpected character @")
+	assert.EqualError(t, s.errs[1], "Lexical error at line 2, column 5 - Decimal 0.0000000000000000001 exceeds 18 fractional digits")
+	assert.EqualError(t, s.errs[2], "Lexical error at line 3, column 5 - Unterminated string")
 }
 
 func TestScanMultipleTokens(t *testing.T) {
@@ -271,9 +300,61 @@ func TestScanMultipleTokens(t *testing.T) {
 	assert.Len(t, tokens, 5)
 	assert.Equal(t, TokenPrint, tokens[0].Type)
 	assert.Equal(t, TokenNumber, tokens[1].Type)
-	assert.Equal(t, float64(2), tokens[1].Literal)
+	assert.Equal(t, Int(2), tokens[1].Literal)
 	assert.Equal(t, TokenPlus, tokens[2].Type)
 	assert.Equal(t, TokenNumber, tokens[3].Type)
-	assert.Equal(t, float64(2), tokens[3].Literal)
+	assert.Equal(t, Int(2), tokens[3].Literal)
 	assert.Equal(t, TokenEndOfFile, tokens[4].Type)
 }
+
+func TestScanTokenSquareBrackets(t *testing.T) {
+	s := newScanner("[]")
+	s.scanToken()
+	s.scanToken()
+	assert.Equal(t, TokenLeftSquare, s.tokens[0].Type)
+	assert.Equal(t, TokenRightSquare, s.tokens[1].Type)
+}
+
+func TestScanTokenColon(t *testing.T) {
+	s := newScanner(":")
+	s.scanToken()
+	assert.Equal(t, TokenColon, s.tokens[0].Type)
+}
+
+func TestScanTokenColumns(t *testing.T) {
+	s := newScanner("a = \"x\ny\"\n  b")
+	tokens := s.scanTokens()
+	assert.Equal(t, 1, tokens[0].Column)
+	assert.Equal(t, 3, tokens[1].Column)
+	assert.Equal(t, 1, tokens[2].Line)
+	assert.Equal(t, 5, tokens[2].Column)
+	assert.Equal(t, 3, tokens[3].Line)
+	assert.Equal(t, 3, tokens[3].Column)
+}
+
+func TestScanTokenOffsets(t *testing.T) {
+	s := newScanner("a = \"ü€\"\nb")
+	tokens := s.scanTokens()
+	assert.Equal(t, 0, tokens[0].Start)
+	assert.Equal(t, 1, tokens[0].End)
+	assert.Equal(t, 2, tokens[1].Start)
+	assert.Equal(t, 3, tokens[1].End)
+	assert.Equal(t, 4, tokens[2].Start)
+	assert.Equal(t, 11, tokens[2].End)
+	assert.Equal(t, 5, tokens[2].Column)
+	assert.Equal(t, 12, tokens[3].Start)
+	assert.Equal(t, 13, tokens[3].End)
+	assert.Equal(t, 13, tokens[4].Start)
+}
+
+func TestScanTokenOffsetsInvalidUTF8(t *testing.T) {
+	//The invalid byte is one byte long in the code even if it is scanned as U+FFFD
+	code := "a = \"\xff\"\nb"
+	s := newScanner(code)
+	tokens := s.scanTokens()
+	assert.Equal(t, 4, tokens[2].Start)
+	assert.Equal(t, 7, tokens[2].End)
+	assert.Equal(t, 8, tokens[3].Start)
+	assert.Equal(t, "b", code[tokens[3].Start:tokens[3].End])
+	assert.Equal(t, len(code), tokens[4].Start)
+}
@@ -0,0 +1,30 @@
+package uniris
+
+//Span locates a piece of code in the source.
+//Line and Column are the position of its first character, Start and End its byte offsets (End excluded).
+type Span struct {
+	Line   int
+	Column int
+	Start  int
+	End    int
+}
+
+func (s Span) span() Span {
+	return s
+}
+
+//tokenSpan returns the location of a token
+func tokenSpan(tok token) Span {
+	return Span{
+		Line:   tok.Line,
+		Column: tok.Column,
+		Start:  tok.Start,
+		End:    tok.End,
+	}
+}
+
+//join returns the location going from the start of a span to the end of another one
+func join(from Span, to Span) Span {
+	from.End = to.End
+	return from
+}
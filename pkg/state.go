@@ -0,0 +1,472 @@
+package uniris
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+//StateStore keeps the contract state variables between the executions and is implemented by the host.
+//A key is the deployment id given to Environment.SetStateStore, a slash, the contract name, a dot and the variable name,
+//such as 2c26b46b/Apostille.refugeeID. Several deployments can share a store as long as their ids differ.
+type StateStore interface {
+	//Get returns the encoded value of a key and whether it exists
+	Get(key string) ([]byte, bool, error)
+
+	//Put writes the encoded value of a key
+	Put(key string, value []byte) error
+
+	//Delete removes a key, deleting a missing key is not an error
+	Delete(key string) error
+
+	//Iterate calls fn for each key in ascending order until fn returns an error
+	Iterate(fn func(key string, value []byte) error) error
+}
+
+//BatchStateStore is a StateStore able to write several keys at once.
+//The state of a successful execution is written with PutAll when the store implements it, with Put otherwise.
+type BatchStateStore interface {
+	StateStore
+
+	//PutAll writes all the values or none of them
+	PutAll(values map[string][]byte) error
+}
+
+//MemoryStateStore is a StateStore keeping the state in memory
+type MemoryStateStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+//NewMemoryStateStore creates an empty in-memory state store
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{
+		values: make(map[string][]byte, 0),
+	}
+}
+
+//Get returns the encoded value of a key and whether it exists
+func (s *MemoryStateStore) Get(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, exist := s.values[key]
+	return append([]byte{}, v...), exist, nil
+}
+
+//Put writes the encoded value of a key
+func (s *MemoryStateStore) Put(key string, value []byte) error {
+	return s.PutAll(map[string][]byte{key: value})
+}
+
+//PutAll writes all the values at once
+func (s *MemoryStateStore) PutAll(values map[string][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range values {
+		s.values[k] = append([]byte{}, v...)
+	}
+	return nil
+}
+
+//Delete removes a key
+func (s *MemoryStateStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+//Iterate calls fn for each key in ascending order.
+//fn receives a snapshot and can use the store.
+func (s *MemoryStateStore) Iterate(fn func(key string, value []byte) error) error {
+	s.mu.Lock()
+	snapshot := make(map[string][]byte, len(s.values))
+	for k, v := range s.values {
+		snapshot[k] = v
+	}
+	s.mu.Unlock()
+	return iterateSorted(snapshot, fn)
+}
+
+//FileStateStore is a StateStore keeping the state in a JSON file.
+//Every write replaces the whole file through a rename so that a crash never leaves it half written.
+type FileStateStore struct {
+	mu     sync.Mutex
+	path   string
+	values map[string][]byte
+}
+
+//NewFileStateStore opens the state kept in a file, the file is created by the first write
+func NewFileStateStore(path string) (*FileStateStore, error) {
+	s := &FileStateStore{
+		path:   path,
+		values: make(map[string][]byte, 0),
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.values); err != nil {
+		return nil, fmt.Errorf("Invalid state file %s: %s", path, err)
+	}
+	return s, nil
+}
+
+//Get returns the encoded value of a key and whether it exists
+func (s *FileStateStore) Get(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, exist := s.values[key]
+	return append([]byte{}, v...), exist, nil
+}
+
+//Put writes the encoded value of a key
+func (s *FileStateStore) Put(key string, value []byte) error {
+	return s.PutAll(map[string][]byte{key: value})
+}
+
+//PutAll writes all the values in a single file replacement
+func (s *FileStateStore) PutAll(values map[string][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := make(map[string][]byte, len(s.values)+len(values))
+	for k, v := range s.values {
+		next[k] = v
+	}
+	for k, v := range values {
+		next[k] = append([]byte{}, v...)
+	}
+	return s.save(next)
+}
+
+//Delete removes a key
+func (s *FileStateStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exist := s.values[key]; !exist {
+		return nil
+	}
+	next := make(map[string][]byte, len(s.values))
+	for k, v := range s.values {
+		if k != key {
+			next[k] = v
+		}
+	}
+	return s.save(next)
+}
+
+//Iterate calls fn for each key in ascending order.
+//fn receives a snapshot and can use the store.
+func (s *FileStateStore) Iterate(fn func(key string, value []byte) error) error {
+	s.mu.Lock()
+	snapshot := s.values
+	s.mu.Unlock()
+	return iterateSorted(snapshot, fn)
+}
+
+//save writes the values to a temporary file then renames it over the state file.
+//The values in memory are only replaced once the file is written.
+func (s *FileStateStore) save(values map[string][]byte) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	s.values = values
+	return nil
+}
+
+func iterateSorted(values map[string][]byte, fn func(key string, value []byte) error) error {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := fn(k, append([]byte{}, values[k]...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//stateKey is the store key of a contract state variable of a deployment
+func stateKey(id string, contract string, field string) string {
+	return id + "/" + contract + "." + field
+}
+
+//pendingState records the contracts used by an execution to write their state back when it succeeds
+type pendingState struct {
+	store     StateStore
+	id        string
+	contracts []*Contract
+}
+
+//field returns the stored value of a contract state variable and whether it exists
+func (p *pendingState) field(contract string, field string) (Value, bool, error) {
+	key := stateKey(p.id, contract, field)
+	data, exist, err := p.store.Get(key)
+	if err != nil || !exist {
+		return nil, false, err
+	}
+	val, err := DecodeState(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("Cannot load %s: %s", key, err)
+	}
+	return val, true, nil
+}
+
+//track records a contract whose state is written back on success.
+//The state of a contract declared by a previous execution is first loaded from the store.
+func (p *pendingState) track(c *Contract, load bool) error {
+	for _, tracked := range p.contracts {
+		if tracked == c {
+			return nil
+		}
+	}
+	if load {
+		for _, f := range c.fields {
+			val, exist, err := p.field(c.Name, f)
+			if err != nil {
+				return err
+			}
+			if exist {
+				c.env.define(f, val)
+			}
+		}
+	}
+	p.contracts = append(p.contracts, c)
+	return nil
+}
+
+//commit encodes then writes the state of the tracked contracts.
+//It returns a function writing back the previous state when the other side effects of the execution fail.
+func (p *pendingState) commit() (func() error, error) {
+	if len(p.contracts) == 0 {
+		return func() error { return nil }, nil
+	}
+	values := make(map[string][]byte, 0)
+	for _, c := range p.contracts {
+		for _, f := range c.fields {
+			key := stateKey(p.id, c.Name, f)
+			data, err := EncodeState(c.env.values[f])
+			if err != nil {
+				return nil, fmt.Errorf("Cannot store %s: %s", key, err)
+			}
+			values[key] = data
+		}
+	}
+
+	previous := make(map[string][]byte, len(values))
+	missing := make([]string, 0)
+	for key := range values {
+		data, exist, err := p.store.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if exist {
+			previous[key] = data
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	if err := p.write(values); err != nil {
+		return nil, err
+	}
+	return func() error {
+		if err := p.write(previous); err != nil {
+			return err
+		}
+		for _, key := range missing {
+			if err := p.store.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+func (p *pendingState) write(values map[string][]byte) error {
+	if batch, ok := p.store.(BatchStateStore); ok {
+		return batch.PutAll(values)
+	}
+	return iterateSorted(values, p.store.Put)
+}
+
+//Tags of the encoded state values
+const (
+	stateNil byte = iota
+	stateBoolean
+	stateInteger
+	stateFloat
+	stateDecimal
+	stateString
+	stateList
+	stateObject
+)
+
+//maxStateDepth bounds the nesting of the stored lists and objects, a list holding itself cannot be stored
+const maxStateDepth = 64
+
+var errTruncatedState = errors.New("Invalid state: unexpected end of data")
+
+//EncodeState encodes a value kept by a StateStore.
+//Functions and contracts cannot be stored.
+func EncodeState(v Value) ([]byte, error) {
+	w := &artifactWriter{}
+	if err := w.value(v, 0); err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+//DecodeState decodes a value encoded by EncodeState
+func DecodeState(data []byte) (Value, error) {
+	r := &artifactReader{data: data}
+	v := r.value(0)
+	if r.err == errTruncatedArtifact {
+		return nil, errTruncatedState
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.data) > 0 {
+		return nil, errors.New("Invalid state: unexpected data after the value")
+	}
+	return v, nil
+}
+
+func (w *artifactWriter) value(v Value, depth int) error {
+	if depth > maxStateDepth {
+		return fmt.Errorf("Cannot store values nested more than %d times", maxStateDepth)
+	}
+	switch c := v.(type) {
+	case nil:
+		w.buf.WriteByte(stateNil)
+	case Bool:
+		w.buf.WriteByte(stateBoolean)
+		if c {
+			w.buf.WriteByte(1)
+		} else {
+			w.buf.WriteByte(0)
+		}
+	case Int:
+		w.buf.WriteByte(stateInteger)
+		w.int(int64(c))
+	case Float:
+		w.buf.WriteByte(stateFloat)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], math.Float64bits(float64(c)))
+		w.buf.Write(b[:])
+	case Decimal:
+		w.buf.WriteByte(stateDecimal)
+		w.string(c.String())
+	case String:
+		w.buf.WriteByte(stateString)
+		w.string(string(c))
+	case *list:
+		w.buf.WriteByte(stateList)
+		w.uint(uint64(len(c.elements)))
+		for _, el := range c.elements {
+			if err := w.value(el, depth+1); err != nil {
+				return err
+			}
+		}
+	case *object:
+		w.buf.WriteByte(stateObject)
+		w.uint(uint64(len(c.keys)))
+		for _, k := range c.keys {
+			if err := w.value(k, depth+1); err != nil {
+				return err
+			}
+			if err := w.value(c.lookup(k), depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("Cannot store a %s", typeName(v))
+	}
+	return nil
+}
+
+func (r *artifactReader) value(depth int) Value {
+	if depth > maxStateDepth {
+		r.fail(fmt.Errorf("Invalid state: values nested more than %d times", maxStateDepth))
+		return nil
+	}
+	switch tag := r.byte(); tag {
+	case stateNil:
+		return nil
+	case stateBoolean:
+		return Bool(r.byte() == 1)
+	case stateInteger:
+		return Int(r.int())
+	case stateFloat:
+		if len(r.data) < 8 {
+			r.fail(errTruncatedArtifact)
+			return nil
+		}
+		f := math.Float64frombits(binary.BigEndian.Uint64(r.data))
+		r.data = r.data[8:]
+		return Float(f)
+	case stateDecimal:
+		d, err := ParseDecimal(r.string())
+		if err != nil && r.err == nil {
+			r.fail(fmt.Errorf("Invalid state: %s", err))
+		}
+		return d
+	case stateString:
+		return String(r.string())
+	case stateList:
+		n := r.count()
+		elements := make([]Value, 0, n)
+		for i := 0; i < n && r.err == nil; i++ {
+			elements = append(elements, r.value(depth+1))
+		}
+		return newList(elements...)
+	case stateObject:
+		n := r.count()
+		o := newObject()
+		for i := 0; i < n && r.err == nil; i++ {
+			k := r.value(depth + 1)
+			v := r.value(depth + 1)
+			if r.err != nil {
+				break
+			}
+			if err := o.set(k, v); err != nil {
+				r.fail(fmt.Errorf("Invalid state: %s", err))
+			}
+		}
+		return o
+	default:
+		r.fail(fmt.Errorf("Invalid state: unknown value tag %d", tag))
+		return nil
+	}
+}
@@ -0,0 +1,264 @@
+package uniris
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const counterContract = `
+contract Counter {
+    count = 0
+    owner = "alice"
+
+    function increment() {
+        count = count + 1
+        return count
+    }
+
+    function fail() {
+        count = 100
+        missing()
+    }
+}
+`
+
+func storedValue(t *testing.T, store StateStore, key string) Value {
+	data, exist, err := store.Get(key)
+	assert.Nil(t, err)
+	assert.True(t, exist)
+	val, err := DecodeState(data)
+	assert.Nil(t, err)
+	return val
+}
+
+func TestMemoryStateStore(t *testing.T) {
+	s := NewMemoryStateStore()
+	assert.Nil(t, s.Put("b", []byte("2")))
+	assert.Nil(t, s.Put("a", []byte("1")))
+
+	v, exist, err := s.Get("a")
+	assert.Nil(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, []byte("1"), v)
+
+	var keys []string
+	assert.Nil(t, s.Iterate(func(key string, value []byte) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	assert.Nil(t, s.Delete("a"))
+	assert.Nil(t, s.Delete("a"))
+	_, exist, _ = s.Get("a")
+	assert.False(t, exist)
+
+	err = s.Iterate(func(key string, value []byte) error {
+		return errors.New("stop")
+	})
+	assert.EqualError(t, err, "stop")
+}
+
+func TestFileStateStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := NewFileStateStore(path)
+	assert.Nil(t, err)
+	_, exist, _ := s.Get("a")
+	assert.False(t, exist)
+
+	assert.Nil(t, s.PutAll(map[string][]byte{"a": []byte("1"), "b": []byte("2")}))
+	assert.Nil(t, s.Delete("b"))
+
+	s, err = NewFileStateStore(path)
+	assert.Nil(t, err)
+	v, exist, _ := s.Get("a")
+	assert.True(t, exist)
+	assert.Equal(t, []byte("1"), v)
+	_, exist, _ = s.Get("b")
+	assert.False(t, exist)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	_, err = NewFileStateStore(path)
+	assert.EqualError(t, err, "Invalid state file "+path+": unexpected end of JSON input")
+}
+
+func TestEncodeState(t *testing.T) {
+	o := newObject()
+	o.set(String("a"), newList(Int(-1), Float(1.5), MustParseDecimal("0.1"), Bool(true), nil))
+	o.set(Int(2), String("two"))
+
+	for _, v := range []Value{nil, Bool(false), Int(42), Float(-0.5), MustParseDecimal("12.50"), String("é"), newList(), o} {
+		data, err := EncodeState(v)
+		assert.Nil(t, err)
+		decoded, err := DecodeState(data)
+		assert.Nil(t, err)
+		assert.Equal(t, KindOf(v), KindOf(decoded))
+		assert.True(t, Equal(v, decoded), format(v))
+	}
+
+	_, err := EncodeState(lenFunc{})
+	assert.EqualError(t, err, "Cannot store a function")
+
+	l := newList()
+	l.elements = append(l.elements, l)
+	_, err = EncodeState(l)
+	assert.EqualError(t, err, "Cannot store values nested more than 64 times")
+
+	_, err = DecodeState([]byte{stateString, 5, 'a'})
+	assert.EqualError(t, err, "Invalid state: unexpected end of data")
+	_, err = DecodeState([]byte{42})
+	assert.EqualError(t, err, "Invalid state: unknown value tag 42")
+	_, err = DecodeState([]byte{stateNil, stateNil})
+	assert.EqualError(t, err, "Invalid state: unexpected data after the value")
+}
+
+func TestContractStatePersists(t *testing.T) {
+	store := NewMemoryStateStore()
+
+	res, err := NewInterpreter(WithStateStore(store, "app")).Run(counterContract + "Counter.increment()")
+	assert.Nil(t, err)
+	assert.Equal(t, "1\n", res.Output)
+	assert.Equal(t, Int(1), storedValue(t, store, "app/Counter.count"))
+	assert.Equal(t, String("alice"), storedValue(t, store, "app/Counter.owner"))
+
+	//The initializers are only evaluated when the store has no value
+	res, err = NewInterpreter(WithStateStore(store, "app")).Run(counterContract + "Counter.increment()")
+	assert.Nil(t, err)
+	assert.Equal(t, "2\n", res.Output)
+
+	b, err := Compile(counterContract)
+	assert.Nil(t, err)
+	env := NewEnvironment(nil)
+	env.SetStateStore(store, "app")
+	res, err = b.Run(env)
+	assert.Nil(t, err)
+	assert.Equal(t, Int(2), res.Contract.State()["count"])
+
+	val, err := res.Contract.Call("increment")
+	assert.Nil(t, err)
+	assert.Equal(t, Int(3), val)
+	assert.Equal(t, Int(3), storedValue(t, store, "app/Counter.count"))
+}
+
+func TestContractStateOnlyWrittenOnSuccess(t *testing.T) {
+	store := NewMemoryStateStore()
+	in := NewInterpreter(WithStateStore(store, "app"))
+	res, err := in.Run(counterContract)
+	assert.Nil(t, err)
+	c := res.Contract
+
+	_, err = c.Call("fail")
+	assert.EqualError(t, err, "Runtime error at missing of line 13, column 9 - Undefined variable missing")
+	assert.Equal(t, Int(0), storedValue(t, store, "app/Counter.count"))
+
+	//The next call starts again from the stored state
+	val, err := c.Call("increment")
+	assert.Nil(t, err)
+	assert.Equal(t, Int(1), val)
+
+	//A contract declared by a previous run is loaded before being used
+	data, _ := EncodeState(Int(10))
+	assert.Nil(t, store.Put("app/Counter.count", data))
+	res, err = in.Run("Counter.increment()")
+	assert.Nil(t, err)
+	assert.Equal(t, "11\n", res.Output)
+
+	_, err = in.Run(`
+contract Broken {
+    f = 0
+    function set() {
+        f = set
+    }
+}
+Broken.set()
+`)
+	assert.EqualError(t, err, "Cannot store app/Broken.f: Cannot store a function")
+	_, exist, _ := store.Get("app/Broken.f")
+	assert.False(t, exist)
+}
+
+func TestContractStateFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	for _, expected := range []string{"1\n", "2\n"} {
+		store, err := NewFileStateStore(path)
+		assert.Nil(t, err)
+		res, err := NewInterpreter(WithStateStore(store, "app")).Run(counterContract + "Counter.increment()")
+		assert.Nil(t, err)
+		assert.Equal(t, expected, res.Output)
+	}
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"app/Counter.count": "Kg=="}`), 0644))
+	store, err := NewFileStateStore(path)
+	assert.Nil(t, err)
+	_, err = NewInterpreter(WithStateStore(store, "app")).Run(counterContract)
+	assert.EqualError(t, err, "Cannot load app/Counter.count: Invalid state: unknown value tag 42")
+}
+
+//refusingLedger accepts the sends but refuses to commit them
+type refusingLedger struct {
+	*MemoryLedger
+}
+
+func (l refusingLedger) Transfer(transfers []Transfer) error {
+	return errors.New("Ledger unavailable")
+}
+
+func TestContractStateAndTransfersTogether(t *testing.T) {
+	const payer = `
+contract Payer {
+    paid = 0
+    f = 0
+    function pay() {
+        send("alice", 3)
+        paid = paid + 3
+    }
+    function broken() {
+        send("alice", 3)
+        f = broken
+    }
+}
+`
+	ledger := NewMemoryLedger(map[string]Decimal{"contract": MustParseDecimal("10")})
+	store := NewMemoryStateStore()
+
+	_, err := NewInterpreter(WithStateStore(store, "app"), WithLedger(ledger, "contract")).Run(payer + "Payer.broken()")
+	assert.EqualError(t, err, "Cannot store app/Payer.f: Cannot store a function")
+	balance, _ := ledger.Balance("contract")
+	assert.Equal(t, "10", balance.String())
+	_, exist, _ := store.Get("app/Payer.paid")
+	assert.False(t, exist)
+
+	in := NewInterpreter(WithStateStore(store, "app"), WithLedger(ledger, "contract"))
+	_, err = in.Run(payer + "Payer.pay()")
+	assert.Nil(t, err)
+	assert.Equal(t, Int(3), storedValue(t, store, "app/Payer.paid"))
+
+	refusing := NewInterpreter(WithStateStore(store, "app"), WithLedger(refusingLedger{ledger}, "contract"))
+	_, err = refusing.Run(payer + "Payer.pay()")
+	assert.EqualError(t, err, "Ledger unavailable")
+	assert.Equal(t, Int(3), storedValue(t, store, "app/Payer.paid"))
+
+	_, err = NewInterpreter(WithStateStore(store, "app"), WithLedger(refusingLedger{ledger}, "contract")).Run(`
+contract Other {
+    count = 1
+}
+send("alice", 1)
+`)
+	assert.EqualError(t, err, "Ledger unavailable")
+	_, exist, _ = store.Get("app/Other.count")
+	assert.False(t, exist)
+}
+
+func TestContractStateIsNamespaced(t *testing.T) {
+	store := NewMemoryStateStore()
+	for _, id := range []string{"first", "second", "first"} {
+		_, err := NewInterpreter(WithStateStore(store, id)).Run(counterContract + "Counter.increment()")
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, Int(2), storedValue(t, store, "first/Counter.count"))
+	assert.Equal(t, Int(1), storedValue(t, store, "second/Counter.count"))
+}
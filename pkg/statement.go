@@ -5,35 +5,53 @@ import (
 )
 
 type statement interface {
-	evaluate(env *Environment) (interface{}, error)
+	evaluate(env *Environment) (Value, error)
+	span() Span
 }
 
 type expressionStmt struct {
+	Span
 	exp expression
 }
 
-func (stmt expressionStmt) evaluate(env *Environment) (interface{}, error) {
+func (stmt expressionStmt) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasExpressionStmt); err != nil {
+		return nil, err
+	}
+
 	return stmt.exp.evaluate(env)
 }
 
 type printStmt struct {
+	Span
 	exp expression
 }
 
-func (stmt printStmt) evaluate(env *Environment) (interface{}, error) {
+func (stmt printStmt) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasPrint); err != nil {
+		return nil, err
+	}
+
 	value, err := stmt.exp.evaluate(env)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("%v\n", value)
+	if err := env.print(value); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
 type blockStmt struct {
+	Span
 	statements []statement
 }
 
-func (stmt blockStmt) evaluate(env *Environment) (interface{}, error) {
+func (stmt blockStmt) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasBlock); err != nil {
+		return nil, err
+	}
+
 	newenvironment := NewEnvironment(env)
 
 	for _, st := range stmt.statements {
@@ -55,12 +73,17 @@ func (stmt blockStmt) evaluate(env *Environment) (interface{}, error) {
 }
 
 type ifStatement struct {
+	Span
 	cond     expression
 	thenStmt statement
 	elseStmt statement
 }
 
-func (stmt ifStatement) evaluate(env *Environment) (interface{}, error) {
+func (stmt ifStatement) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasIf); err != nil {
+		return nil, err
+	}
+
 	cond, err := stmt.cond.evaluate(env)
 	if err != nil {
 		return nil, err
@@ -71,19 +94,25 @@ func (stmt ifStatement) evaluate(env *Environment) (interface{}, error) {
 		}
 	} else {
 		if stmt.elseStmt != nil {
-			elseStmt := stmt.elseStmt
-			elseStmt.evaluate(env)
+			if _, err := stmt.elseStmt.evaluate(env); err != nil {
+				return nil, err
+			}
 		}
 	}
 	return nil, nil
 }
 
 type whileStatement struct {
+	Span
 	cond expression
 	body statement
 }
 
-func (stmt whileStatement) evaluate(env *Environment) (interface{}, error) {
+func (stmt whileStatement) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasWhile); err != nil {
+		return nil, err
+	}
+
 	for {
 		val, err := stmt.cond.evaluate(env)
 		if err != nil {
@@ -92,30 +121,189 @@ func (stmt whileStatement) evaluate(env *Environment) (interface{}, error) {
 		if !isTruthy(val) {
 			break
 		}
-		stmt.body.evaluate(env)
+		if _, err := stmt.body.evaluate(env); err != nil {
+			return nil, err
+		}
+		if err := env.useGas(gasLoopIteration); err != nil {
+			return nil, err
+		}
+		if err := env.checkContext(); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+//for init; cond; increment: keeps its parts so the syntax tree shows the loop as written
+type forStatement struct {
+	Span
+	init      statement
+	cond      expression
+	increment expression
+	body      statement
+}
+
+func (stmt forStatement) evaluate(env *Environment) (Value, error) {
+	return stmt.desugar().evaluate(env)
+}
+
+//desugar returns the while loop equivalent to the for loop, spanning the whole for statement
+func (stmt forStatement) desugar() statement {
+	body := stmt.body
+	if stmt.increment != nil {
+		body = blockStmt{
+			Span: stmt.Span,
+			statements: []statement{
+				body,
+				expressionStmt{
+					Span: stmt.increment.span(),
+					exp:  stmt.increment,
+				},
+			},
+		}
+	}
+
+	cond := stmt.cond
+	if cond == nil {
+		cond = literalExpression{
+			Span:  stmt.Span,
+			value: Bool(true),
+		}
+	}
+	body = whileStatement{Span: stmt.Span, body: body, cond: cond}
+
+	if stmt.init != nil {
+		body = blockStmt{
+			Span: stmt.Span,
+			statements: []statement{
+				stmt.init,
+				body,
+			},
+		}
+	}
+	return body
+}
+
+//for name in collection: iterates over list elements or object keys
+type forInStatement struct {
+	Span
+	name       token
+	collection expression
+	body       statement
+}
+
+func (stmt forInStatement) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasWhile); err != nil {
+		return nil, err
+	}
+
+	coll, err := stmt.collection.evaluate(env)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := iterationItems(coll)
+	if err != nil {
+		return nil, runtimeError(stmt.name, err)
+	}
+
+	for _, item := range items {
+		if err := env.useGas(gasLoopIteration); err != nil {
+			return nil, err
+		}
+		if err := env.checkContext(); err != nil {
+			return nil, err
+		}
+		loopEnv := NewEnvironment(env)
+		loopEnv.define(stmt.name.Lexeme, item)
+		if _, err := stmt.body.evaluate(loopEnv); err != nil {
+			return nil, err
+		}
 	}
 	return nil, nil
 }
 
+//iterationItems returns a copy of the list elements or of the object keys
+func iterationItems(coll Value) ([]Value, error) {
+	var items []Value
+	switch c := coll.(type) {
+	case *list:
+		items = append(items, c.elements...)
+	case *object:
+		items = append(items, c.keys...)
+	default:
+		return nil, fmt.Errorf("Can only iterate over lists and objects, got %s", typeName(coll))
+	}
+	return items, nil
+}
+
 type funcStatement struct {
+	Span
 	name   token
 	params []token
 	body   blockStmt
 }
 
-func (stmt funcStatement) evaluate(env *Environment) (interface{}, error) {
+func (stmt funcStatement) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasFunction); err != nil {
+		return nil, err
+	}
+
 	f := function{
-		declaration: stmt,
+		declaration: &stmt,
+	}
+	if err := env.assign(stmt.name.Lexeme, f); err != nil {
+		return nil, err
 	}
-	env.Set(stmt.name.Lexeme, f)
 	return nil, nil
 }
 
+//contract Name { state variables and functions }
+type contractStatement struct {
+	Span
+	name      token
+	state     []assignExpression
+	functions []funcStatement
+}
+
+func (stmt contractStatement) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasContract); err != nil {
+		return nil, err
+	}
+
+	c := newContract(env, stmt.name.Lexeme)
+	for i, f := range stmt.functions {
+		c.addFunction(f.name.Lexeme, function{declaration: &stmt.functions[i]})
+	}
+	for _, s := range stmt.state {
+		init := s.exp
+		err := c.initField(env.exec, s.op.Lexeme, func() (Value, error) {
+			return init.evaluate(c.env)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := env.exec.track(c, false); err != nil {
+		return nil, err
+	}
+
+	if err := env.assign(stmt.name.Lexeme, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 type returnStatement struct {
+	Span
 	value expression
 }
 
-func (stmt returnStatement) evaluate(env *Environment) (interface{}, error) {
+func (stmt returnStatement) evaluate(env *Environment) (Value, error) {
+	if err := env.useGas(gasReturn); err != nil {
+		return nil, err
+	}
+
 	value, err := stmt.value.evaluate(env)
 	if err != nil {
 		return nil, err
@@ -128,13 +316,12 @@ func (stmt returnStatement) evaluate(env *Environment) (interface{}, error) {
 	return nil, nil
 }
 
-func isTruthy(val interface{}) bool {
-	if val == nil {
+func isTruthy(val Value) bool {
+	switch v := val.(type) {
+	case nil:
 		return false
-	}
-	switch val.(type) {
-	case bool:
-		return val.(bool)
+	case Bool:
+		return bool(v)
 	}
 	return true
 }
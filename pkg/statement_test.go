@@ -0,0 +1,65 @@
+package uniris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForInStatement(t *testing.T) {
+	env := NewEnvironment(nil)
+	seen := newList()
+	env.Set("seen", seen)
+
+	o := newObject()
+	o.set(String("a"), Float(1))
+	o.set(String("b"), Float(2))
+
+	stmt := forInStatement{
+		name:       token{Lexeme: "k"},
+		collection: literalExpression{value: o},
+		body: expressionStmt{
+			exp: callExpression{
+				callee: literalExpression{value: pushFunc{}},
+				args: []expression{
+					variableExpression{op: token{Lexeme: "seen"}},
+					variableExpression{op: token{Lexeme: "k"}},
+				},
+			},
+		},
+	}
+	_, err := stmt.evaluate(env)
+	assert.Nil(t, err)
+	assert.Equal(t, []Value{String("a"), String("b")}, seen.elements)
+
+	stmt.collection = literalExpression{value: newList(Float(3))}
+	_, err = stmt.evaluate(env)
+	assert.Nil(t, err)
+	assert.Equal(t, []Value{String("a"), String("b"), Float(3)}, seen.elements)
+
+	_, err = env.Get("k")
+	assert.Error(t, err)
+
+	stmt.collection = literalExpression{value: Float(3)}
+	_, err = stmt.evaluate(env)
+	assert.EqualError(t, err, "Runtime error at k of line 0, column 0 - Can only iterate over lists and objects, got number")
+}
+
+func TestIfStatementElseError(t *testing.T) {
+	stmt := ifStatement{
+		cond:     literalExpression{value: Bool(false)},
+		thenStmt: expressionStmt{exp: literalExpression{}},
+		elseStmt: expressionStmt{exp: variableExpression{op: token{Lexeme: "a"}}},
+	}
+	_, err := stmt.evaluate(NewEnvironment(nil))
+	assert.EqualError(t, err, "Runtime error at a of line 0, column 0 - Undefined variable a")
+}
+
+func TestWhileStatementBodyError(t *testing.T) {
+	stmt := whileStatement{
+		cond: literalExpression{value: Bool(true)},
+		body: expressionStmt{exp: variableExpression{op: token{Lexeme: "a"}}},
+	}
+	_, err := stmt.evaluate(NewEnvironment(nil))
+	assert.EqualError(t, err, "Runtime error at a of line 0, column 0 - Undefined variable a")
+}
@@ -0,0 +1,22 @@
+package uniris
+
+//Transaction describes the transaction triggering the contract execution
+type Transaction struct {
+	Address         string
+	SenderPublicKey string
+	Amount          Decimal
+	Timestamp       int64
+	Data            string
+}
+
+//object exposes the transaction to the scripts as a read-only object
+func (tx Transaction) object() *object {
+	o := newObject()
+	o.set(String("address"), String(tx.Address))
+	o.set(String("senderPublicKey"), String(tx.SenderPublicKey))
+	o.set(String("amount"), tx.Amount)
+	o.set(String("timestamp"), Int(tx.Timestamp))
+	o.set(String("data"), String(tx.Data))
+	o.readOnly = true
+	return o
+}
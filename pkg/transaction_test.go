@@ -0,0 +1,67 @@
+package uniris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const walletContract = `
+contract Wallet {
+    deposits = {}
+
+    function receive() {
+        deposits[transaction.senderPublicKey] = transaction.amount
+        return transaction.address + "@" + transaction.timestamp + ":" + transaction.data
+    }
+
+    function tamper() {
+        transaction.amount = 0
+    }
+}
+`
+
+func TestTransactionObject(t *testing.T) {
+	o := Transaction{
+		Address:         "addr",
+		SenderPublicKey: "key",
+		Amount:          MustParseDecimal("10"),
+		Timestamp:       1000,
+		Data:            "hello",
+	}.object()
+
+	assert.Equal(t, []Value{String("address"), String("senderPublicKey"), String("amount"), String("timestamp"), String("data")}, o.keys)
+	assert.Equal(t, "10", o.lookup(String("amount")).(Decimal).String())
+	assert.EqualError(t, o.set(String("amount"), Float(0)), "Cannot modify a read-only object")
+}
+
+func TestContractTransaction(t *testing.T) {
+	env := NewEnvironment(nil)
+	res, err := Interpret(walletContract, env)
+	assert.Nil(t, err)
+
+	env.SetTransaction(Transaction{
+		Address:         "addr",
+		SenderPublicKey: "alice",
+		Amount:          MustParseDecimal("10"),
+		Timestamp:       1000,
+		Data:            "hello",
+	})
+	val, err := res.Contract.Call("receive")
+	assert.Nil(t, err)
+	assert.Equal(t, String("addr@1000:hello"), val)
+
+	deposits := res.Contract.State()["deposits"].(*object)
+	assert.Equal(t, "10", deposits.lookup(String("alice")).(Decimal).String())
+
+	_, err = res.Contract.Call("tamper")
+	assert.EqualError(t, err, "Runtime error at amount of line 11, column 21 - Cannot modify a read-only object")
+}
+
+func TestContractWithoutTransaction(t *testing.T) {
+	res, err := Interpret(walletContract, nil)
+	assert.Nil(t, err)
+
+	_, err = res.Contract.Call("receive")
+	assert.EqualError(t, err, "Runtime error at transaction of line 6, column 18 - No transaction in the execution context")
+}
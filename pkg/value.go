@@ -0,0 +1,399 @@
+package uniris
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+//Kind identifies the type of a script value
+type Kind uint8
+
+//Kinds of the script values
+const (
+	KindNil Kind = iota
+	KindBool
+	KindInt
+	KindFloat
+	KindDecimal
+	KindString
+	KindList
+	KindObject
+	KindFunction
+	KindContract
+)
+
+var kindNames = [...]string{
+	KindNil:      "nil",
+	KindBool:     "boolean",
+	KindInt:      "integer",
+	KindFloat:    "float",
+	KindDecimal:  "decimal",
+	KindString:   "string",
+	KindList:     "list",
+	KindObject:   "object",
+	KindFunction: "function",
+	KindContract: "contract",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+//Value is a value manipulated by the scripts.
+//The set of values is closed, only the types of this package implement it.
+//The nil value of the scripts is a nil Value.
+type Value interface {
+	Kind() Kind
+	String() string
+	isValue()
+}
+
+//Bool is a boolean value
+type Bool bool
+
+//Int is an integer number
+type Int int64
+
+//Float is a floating point number
+type Float float64
+
+//String is a text value
+type String string
+
+func (b Bool) Kind() Kind {
+	return KindBool
+}
+
+func (b Bool) String() string {
+	return strconv.FormatBool(bool(b))
+}
+
+func (b Bool) isValue() {}
+
+func (i Int) Kind() Kind {
+	return KindInt
+}
+
+func (i Int) String() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+func (i Int) isValue() {}
+
+func (f Float) Kind() Kind {
+	return KindFloat
+}
+
+func (f Float) String() string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 64)
+}
+
+func (f Float) isValue() {}
+
+func (s String) Kind() Kind {
+	return KindString
+}
+
+func (s String) String() string {
+	return string(s)
+}
+
+func (s String) isValue() {}
+
+func (d Decimal) Kind() Kind {
+	return KindDecimal
+}
+
+func (d Decimal) isValue() {}
+
+func (l *list) Kind() Kind {
+	return KindList
+}
+
+func (l *list) isValue() {}
+
+func (o *object) Kind() Kind {
+	return KindObject
+}
+
+func (o *object) isValue() {}
+
+func (c *Contract) Kind() Kind {
+	return KindContract
+}
+
+func (c *Contract) isValue() {}
+
+//builtin gives the functions implemented in Go their value methods
+type builtin struct{}
+
+func (b builtin) Kind() Kind {
+	return KindFunction
+}
+
+func (b builtin) String() string {
+	return "<built-in function>"
+}
+
+func (b builtin) isValue() {}
+
+//KindOf returns the kind of a value, KindNil for nil
+func KindOf(v Value) Kind {
+	if v == nil {
+		return KindNil
+	}
+	return v.Kind()
+}
+
+//format prints a value, nil included
+func format(v Value) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return v.String()
+}
+
+//Equal reports whether two values are equal as the == operator does.
+//Numbers are compared by their exact value whatever their type, so two values are equal keys of an object when they are equal.
+//Lists and objects are compared by content, functions and contracts by identity.
+func Equal(a Value, b Value) bool {
+	return equal(a, b, nil)
+}
+
+//equal compares two values, the lists and objects already being compared are considered equal
+//so that the values holding themselves are compared in a finite time as reflect.DeepEqual does
+func equal(a Value, b Value, comparing map[[2]Value]bool) bool {
+	switch x := a.(type) {
+	case nil:
+		return b == nil
+	case Int:
+		switch y := b.(type) {
+		case Int:
+			return x == y
+		case Float, Decimal:
+			return sameKey(a, b)
+		}
+		return false
+	case Float:
+		switch y := b.(type) {
+		case Float:
+			return x == y
+		case Int, Decimal:
+			return sameKey(a, b)
+		}
+		return false
+	case Decimal:
+		switch y := b.(type) {
+		case Decimal:
+			return x.Cmp(y) == 0
+		case Int, Float:
+			return sameKey(a, b)
+		}
+		return false
+	case *list:
+		y, ok := b.(*list)
+		if !ok || len(x.elements) != len(y.elements) {
+			return false
+		}
+		if comparing, ok = visit(comparing, x, y); !ok {
+			return true
+		}
+		for i := range x.elements {
+			if !equal(x.elements[i], y.elements[i], comparing) {
+				return false
+			}
+		}
+		return true
+	case *object:
+		y, ok := b.(*object)
+		if !ok || len(x.keys) != len(y.keys) {
+			return false
+		}
+		if comparing, ok = visit(comparing, x, y); !ok {
+			return true
+		}
+		for k, v := range x.values {
+			other, exist := y.values[k]
+			if !exist || !equal(v, other, comparing) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+//visit records the comparison of two containers, it returns false when they are already being compared
+func visit(comparing map[[2]Value]bool, a Value, b Value) (map[[2]Value]bool, bool) {
+	pair := [2]Value{a, b}
+	if comparing[pair] {
+		return comparing, false
+	}
+	if comparing == nil {
+		comparing = make(map[[2]Value]bool, 0)
+	}
+	comparing[pair] = true
+	return comparing, true
+}
+
+//mapKey identifies an object key.
+//A number is identified by its exact value: the integral ones share the Int kind, the others the Decimal kind.
+type mapKey struct {
+	kind Kind
+	repr string
+}
+
+//sameKey compares two numbers of different types by their exact value
+func sameKey(a Value, b Value) bool {
+	x, err := keyOf(a)
+	if err != nil {
+		return false
+	}
+	y, err := keyOf(b)
+	return err == nil && x == y
+}
+
+func keyOf(v Value) (mapKey, error) {
+	switch k := v.(type) {
+	case Bool, Int, String:
+		return mapKey{kind: k.Kind(), repr: k.String()}, nil
+	case Float:
+		f := float64(k)
+		switch {
+		case math.IsNaN(f):
+			return mapKey{}, errors.New("Cannot use NaN as a key")
+		case math.IsInf(f, 0):
+			return mapKey{kind: KindFloat, repr: k.String()}, nil
+		}
+		//A float is a binary fraction, its exact value has as many fractional digits as the power of two dividing it
+		r := new(big.Rat).SetFloat64(f)
+		if r.IsInt() {
+			return mapKey{kind: KindInt, repr: r.Num().String()}, nil
+		}
+		return mapKey{kind: KindDecimal, repr: r.FloatString(r.Denom().BitLen() - 1)}, nil
+	case Decimal:
+		//Decimals are normalized, an integral one has no fractional digits
+		if k.scale == 0 {
+			return mapKey{kind: KindInt, repr: k.String()}, nil
+		}
+		return mapKey{kind: KindDecimal, repr: k.String()}, nil
+	case nil:
+		return mapKey{}, errors.New("Cannot use nil as a key")
+	default:
+		return mapKey{}, fmt.Errorf("Cannot use a %s as a key", typeName(v))
+	}
+}
+
+//Hash returns the hash of a value usable as an object key.
+//Values used as the same key have the same hash, lists, objects, functions and contracts cannot be hashed.
+func Hash(v Value) (uint64, error) {
+	k, err := keyOf(v)
+	if err != nil {
+		return 0, err
+	}
+	h := fnv.New64a()
+	h.Write([]byte{byte(k.kind)})
+	h.Write([]byte(k.repr))
+	return h.Sum64(), nil
+}
+
+//ValueOf converts a Go value into a script value.
+//Booleans, numbers, strings, decimals, slices, maps with string keys and structs are supported.
+func ValueOf(v interface{}) (Value, error) {
+	return fromGo(reflect.ValueOf(v))
+}
+
+//ToGo converts a script value into its natural Go representation:
+//bool, int64, float64, string, Decimal, []interface{} and map[string]interface{}.
+//Object keys which are not strings are converted to their printed form,
+//functions and contracts are kept as is, a list or an object nested in itself is converted to nil.
+func ToGo(v Value) interface{} {
+	return goValue(v, nil)
+}
+
+func goValue(v Value, converting map[Value]bool) interface{} {
+	switch c := v.(type) {
+	case nil:
+		return nil
+	case Bool:
+		return bool(c)
+	case Int:
+		return int64(c)
+	case Float:
+		return float64(c)
+	case String:
+		return string(c)
+	case *list, *object:
+		if converting[v] {
+			return nil
+		}
+		if converting == nil {
+			converting = make(map[Value]bool, 0)
+		}
+		converting[v] = true
+		defer delete(converting, v)
+		if l, ok := c.(*list); ok {
+			elements := make([]interface{}, len(l.elements))
+			for i, el := range l.elements {
+				elements[i] = goValue(el, converting)
+			}
+			return elements
+		}
+		o := c.(*object)
+		values := make(map[string]interface{}, len(o.keys))
+		for _, k := range o.keys {
+			values[k.String()] = goValue(o.lookup(k), converting)
+		}
+		return values
+	default:
+		return v
+	}
+}
+
+//copyValue duplicates the lists and objects held by a value so the copy can be changed without affecting it.
+//A container referenced several times, or nested in itself, is copied once.
+func copyValue(v Value, copies map[Value]Value) Value {
+	switch c := v.(type) {
+	case *list:
+		if cp, exist := copies[c]; exist {
+			return cp
+		}
+		if copies == nil {
+			copies = make(map[Value]Value, 0)
+		}
+		l := &list{elements: make([]Value, len(c.elements))}
+		copies[c] = l
+		for i, el := range c.elements {
+			l.elements[i] = copyValue(el, copies)
+		}
+		return l
+	case *object:
+		if cp, exist := copies[c]; exist {
+			return cp
+		}
+		if copies == nil {
+			copies = make(map[Value]Value, 0)
+		}
+		o := &object{
+			keys:     append([]Value{}, c.keys...),
+			values:   make(map[mapKey]Value, len(c.values)),
+			readOnly: c.readOnly,
+		}
+		copies[c] = o
+		for k, val := range c.values {
+			o.values[k] = copyValue(val, copies)
+		}
+		return o
+	default:
+		return v
+	}
+}
@@ -0,0 +1,168 @@
+package uniris
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKind(t *testing.T) {
+	assert.Equal(t, KindNil, KindOf(nil))
+	assert.Equal(t, KindBool, KindOf(Bool(true)))
+	assert.Equal(t, KindInt, KindOf(Int(1)))
+	assert.Equal(t, KindFloat, KindOf(Float(1.5)))
+	assert.Equal(t, KindDecimal, KindOf(MustParseDecimal("1.5")))
+	assert.Equal(t, KindString, KindOf(String("a")))
+	assert.Equal(t, KindList, KindOf(newList()))
+	assert.Equal(t, KindObject, KindOf(newObject()))
+	assert.Equal(t, KindFunction, KindOf(lenFunc{}))
+	assert.Equal(t, KindContract, KindOf(&Contract{}))
+
+	assert.Equal(t, "integer", KindInt.String())
+	assert.Equal(t, "Kind(42)", Kind(42).String())
+}
+
+func TestValueString(t *testing.T) {
+	assert.Equal(t, "true", Bool(true).String())
+	assert.Equal(t, "-3", Int(-3).String())
+	assert.Equal(t, "1.5", Float(1.5).String())
+	assert.Equal(t, "1e+21", Float(1e21).String())
+	assert.Equal(t, "a", String("a").String())
+	assert.Equal(t, "<nil>", format(nil))
+	assert.Equal(t, "<built-in function>", lenFunc{}.String())
+
+	res, err := Interpret("function f() {}\nf", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "<function f>\n", res.Output)
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal(nil, nil))
+	assert.True(t, Equal(Int(1), Float(1)))
+	assert.True(t, Equal(Float(1), Int(1)))
+	assert.True(t, Equal(Int(2), MustParseDecimal("2")))
+	assert.True(t, Equal(MustParseDecimal("2.50"), MustParseDecimal("2.5")))
+	assert.True(t, Equal(MustParseDecimal("2"), Float(2)))
+	assert.True(t, Equal(Float(0.5), MustParseDecimal("0.5")))
+	assert.False(t, Equal(Float(0.1), MustParseDecimal("0.1")))
+	assert.False(t, Equal(Int(1<<60+1), Float(1<<60)))
+	assert.False(t, Equal(String("1"), Int(1)))
+	assert.False(t, Equal(nil, Bool(false)))
+	assert.True(t, Equal(newList(Int(1), String("a")), newList(Float(1), String("a"))))
+
+	a, b := newObject(), newObject()
+	a.set(String("k"), Int(1))
+	b.set(String("k"), Float(1))
+	assert.True(t, Equal(a, b))
+	b.set(Int(2), nil)
+	assert.False(t, Equal(a, b))
+
+	x, y := newList(Int(1)), newList(Int(1))
+	x.elements = append(x.elements, x)
+	y.elements = append(y.elements, y)
+	assert.True(t, Equal(x, y))
+	y.elements[0] = Int(2)
+	assert.False(t, Equal(x, y))
+	a.set(String("self"), a)
+	assert.True(t, Equal(a, a))
+
+	f := function{declaration: &funcStatement{}}
+	assert.True(t, Equal(f, f))
+	assert.False(t, Equal(f, function{declaration: &funcStatement{}}))
+}
+
+func TestHash(t *testing.T) {
+	one, err := Hash(Int(1))
+	assert.Nil(t, err)
+	for _, v := range []Value{Float(1), MustParseDecimal("1")} {
+		h, err := Hash(v)
+		assert.Nil(t, err)
+		assert.Equal(t, one, h)
+	}
+	str, _ := Hash(String("1"))
+	assert.NotEqual(t, one, str)
+	half, _ := Hash(Float(0.5))
+	decimalHalf, _ := Hash(MustParseDecimal("0.5"))
+	assert.Equal(t, half, decimalHalf)
+	tenth, _ := Hash(Float(0.1))
+	decimalTenth, _ := Hash(MustParseDecimal("0.1"))
+	assert.NotEqual(t, tenth, decimalTenth)
+
+	_, err = Hash(newList())
+	assert.EqualError(t, err, "Cannot use a list as a key")
+	_, err = Hash(nil)
+	assert.EqualError(t, err, "Cannot use nil as a key")
+}
+
+func TestEqualNumbersAndKeys(t *testing.T) {
+	numbers := []Value{
+		Int(0), Int(1), Int(-1), Int(2), Int(1 << 60), Int(1<<60 + 1),
+		Float(0), Float(math.Copysign(0, -1)), Float(1), Float(-1), Float(2), Float(0.5), Float(0.1),
+		Float(1 << 60), Float(1e300), Float(math.Inf(1)), Float(math.Inf(-1)),
+		MustParseDecimal("0"), MustParseDecimal("1.00"), MustParseDecimal("-1"), MustParseDecimal("2"),
+		MustParseDecimal("0.5"), MustParseDecimal("0.1"), MustParseDecimal("1152921504606846976"),
+	}
+	for _, a := range numbers {
+		for _, b := range numbers {
+			x, _ := keyOf(a)
+			y, _ := keyOf(b)
+			assert.Equal(t, x == y, Equal(a, b), "%s (%s) and %s (%s)", a, KindOf(a), b, KindOf(b))
+		}
+	}
+
+	res, err := Interpret("o = {}\no[1.0] = \"x\"\nhas(o, 1d)\n1.0 == 1d\ncontains([1.0], 1d)\n0.5 == 0.5d", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "true\ntrue\ntrue\ntrue\n", res.Output)
+}
+
+func TestObjectKeys(t *testing.T) {
+	res, err := Interpret(`
+o = {name: "alice"}
+o[1] = "one"
+o[true] = "yes"
+o[2.5] = "half"
+o[1.0]
+o[decimal(1)]
+o
+keys(o)
+has(o, 2.5)
+`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "one\none\n{\"name\": \"alice\", 1: \"one\", true: \"yes\", 2.5: \"half\"}\n[\"name\", 1, true, 2.5]\ntrue\n", res.Output)
+
+	_, err = Interpret("o = {}\no[[1]] = 1", nil)
+	assert.EqualError(t, err, "Runtime error at ] of line 2, column 6 - Cannot use a list as a key")
+}
+
+func TestValueOf(t *testing.T) {
+	v, err := ValueOf(map[string]interface{}{"b": []int{1, 2}, "a": 1.5})
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"a\": 1.5, \"b\": [1, 2]}", v.String())
+
+	v, err = ValueOf(Int(3))
+	assert.Nil(t, err)
+	assert.Equal(t, Int(3), v)
+
+	v, err = ValueOf(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, v)
+
+	_, err = ValueOf(make(chan int))
+	assert.EqualError(t, err, "Cannot convert chan int")
+}
+
+func TestToGo(t *testing.T) {
+	o := newObject()
+	o.set(String("a"), newList(Int(1), Float(1.5), String("x"), Bool(true), nil))
+	o.set(Int(2), MustParseDecimal("0.1"))
+
+	assert.Equal(t, map[string]interface{}{
+		"a": []interface{}{int64(1), 1.5, "x", true, nil},
+		"2": MustParseDecimal("0.1"),
+	}, ToGo(o))
+
+	l := newList(Int(1))
+	l.elements = append(l.elements, l)
+	assert.Equal(t, []interface{}{int64(1), nil}, ToGo(l))
+}
@@ -0,0 +1,337 @@
+package uniris
+
+import (
+	"errors"
+	"fmt"
+)
+
+//vmGasCosts is the gas consumed by each virtual machine instruction
+var vmGasCosts = [...]uint64{
+	opConstant:         1,
+	opNil:              1,
+	opPop:              0,
+	opOutput:           0,
+	opGetVar:           1,
+	opSetVar:           2,
+	opDefine:           1,
+	opBinary:           2,
+	opNot:              1,
+	opNegate:           1,
+	opJump:             1,
+	opJumpIfFalse:      1,
+	opJumpIfFalseOrPop: 1,
+	opJumpIfTrueOrPop:  1,
+	opLoop:             1,
+	opCallee:           0,
+	opCall:             10,
+	opList:             2,
+	opObject:           2,
+	opIndex:            2,
+	opIndexSet:         3,
+	opGet:              2,
+	opSetTarget:        0,
+	opSet:              3,
+	opTransaction:      2,
+	opPrint:            5,
+	opPushScope:        1,
+	opPopScope:         0,
+	opFunction:         5,
+	opContract:         10,
+	opReturn:           1,
+	opIterInit:         1,
+	opIterNext:         1,
+}
+
+//iterator walks through the items of a for in loop
+type iterator struct {
+	items []Value
+	next  int
+}
+
+//errInvalidBytecode stops the execution of an instruction which cannot be run, found in corrupted artifacts only
+func errInvalidBytecode(ip int, reason string) error {
+	return fmt.Errorf("Invalid bytecode at instruction %d: %s", ip, reason)
+}
+
+//stackInputs returns the number of values an instruction takes from the stack
+func (c *chunk) stackInputs(in instruction) int {
+	switch in.op {
+	case opPop, opOutput, opSetVar, opDefine, opNot, opNegate, opJumpIfFalse, opJumpIfFalseOrPop, opJumpIfTrueOrPop,
+		opCallee, opGet, opSetTarget, opPrint, opReturn, opIterInit:
+		return 1
+	case opBinary, opIndex, opSet:
+		return 2
+	case opIndexSet:
+		return 3
+	case opCall:
+		return in.arg + 1
+	case opList:
+		return in.arg
+	case opObject:
+		return len(c.constants[in.arg].([]string))
+	default:
+		return 0
+	}
+}
+
+//name returns a variable or a property name of the constants
+func (c *chunk) name(i int) string {
+	return string(c.constants[i].(String))
+}
+
+//run executes a chunk in an environment until its end or a non nil return.
+//The values of the top level expressions are collected in the result when there is one.
+//The iterators of the for in loops being run are kept aside of the values.
+//The operands are checked before each instruction so that a corrupted artifact fails with an error.
+func run(c *chunk, env *Environment, res *Result) (Value, error) {
+	var gas *gasMeter
+	if env.exec != nil {
+		gas = env.exec.gas
+	}
+
+	var iterators []*iterator
+	scopes := 0
+	stack := make([]Value, 0, 8)
+	pop := func() Value {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return v
+	}
+
+	ip := 0
+	for ip < len(c.code) {
+		in := c.code[ip]
+		ip++
+
+		if gas != nil {
+			if err := gas.consume(vmGasCosts[in.op]); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(stack) < c.stackInputs(in) {
+			return nil, errInvalidBytecode(ip-1, "stack underflow")
+		}
+
+		switch in.op {
+		case opConstant:
+			stack = append(stack, c.constants[in.arg].(Value))
+		case opNil:
+			stack = append(stack, nil)
+		case opPop:
+			pop()
+		case opOutput:
+			if res != nil {
+				res.add(pop())
+			} else {
+				pop()
+			}
+		case opGetVar:
+			val, err := env.lookup(c.name(in.arg))
+			if err != nil {
+				return nil, runtimeError(c.tokens[in.tok], err)
+			}
+			stack = append(stack, val)
+		case opSetVar:
+			if err := env.assign(c.name(in.arg), pop()); err != nil {
+				return nil, err
+			}
+			stack = append(stack, nil)
+		case opDefine:
+			env.define(c.name(in.arg), pop())
+		case opBinary:
+			right := pop()
+			left := pop()
+			op := c.tokens[in.tok]
+			val, err := binaryExpression{op: op}.apply(left, right)
+			if err != nil {
+				return nil, runtimeError(op, err)
+			}
+			if err := env.useItemsGas(itemCount(val)); err != nil {
+				return nil, err
+			}
+			stack = append(stack, val)
+		case opNot:
+			stack = append(stack, Bool(!isTruthy(pop())))
+		case opNegate:
+			val, err := negate(pop())
+			if err != nil {
+				return nil, runtimeError(c.tokens[in.tok], err)
+			}
+			stack = append(stack, val)
+		case opJump:
+			ip = in.arg
+		case opJumpIfFalse:
+			if !isTruthy(pop()) {
+				ip = in.arg
+			}
+		case opJumpIfFalseOrPop:
+			if !isTruthy(stack[len(stack)-1]) {
+				ip = in.arg
+			} else {
+				pop()
+			}
+		case opJumpIfTrueOrPop:
+			if isTruthy(stack[len(stack)-1]) {
+				ip = in.arg
+			} else {
+				pop()
+			}
+		case opLoop:
+			if err := env.checkContext(); err != nil {
+				return nil, err
+			}
+			ip = in.arg
+		case opCallee:
+			tok := c.tokens[in.tok]
+			callee := stack[len(stack)-1]
+			if isNondeterministic(callee) && env.isDeterministic() {
+				name := "Function"
+				if tok.Type == TokenIdentifier {
+					name = tok.Lexeme
+				}
+				return nil, runtimeError(tok, fmt.Errorf("%s cannot be called in deterministic mode", name))
+			}
+			if _, ok := callee.(callable); !ok {
+				return nil, runtimeError(tok, fmt.Errorf("Can only call functions, got %s", typeName(callee)))
+			}
+		case opCall:
+			args := make([]Value, in.arg)
+			copy(args, stack[len(stack)-in.arg:])
+			stack = stack[:len(stack)-in.arg]
+			f, ok := pop().(callable)
+			if !ok {
+				return nil, errInvalidBytecode(ip-1, "call without callee")
+			}
+			val, err := f.call(env, args...)
+			if err != nil {
+				return nil, runtimeError(c.tokens[in.tok], err)
+			}
+			stack = append(stack, val)
+		case opList:
+			elements := make([]Value, in.arg)
+			copy(elements, stack[len(stack)-in.arg:])
+			stack = stack[:len(stack)-in.arg]
+			stack = append(stack, newList(elements...))
+		case opObject:
+			keys := c.constants[in.arg].([]string)
+			values := stack[len(stack)-len(keys):]
+			obj := newObject()
+			for i, k := range keys {
+				obj.set(String(k), values[i])
+			}
+			stack = append(stack[:len(stack)-len(keys)], obj)
+		case opIndex:
+			idx := pop()
+			obj := pop()
+			val, err := index(obj, idx)
+			if err != nil {
+				return nil, runtimeError(c.tokens[in.tok], err)
+			}
+			stack = append(stack, val)
+		case opIndexSet:
+			value := pop()
+			idx := pop()
+			obj := pop()
+			if err := setIndex(obj, idx, value); err != nil {
+				return nil, runtimeError(c.tokens[in.tok], err)
+			}
+			stack = append(stack, nil)
+		case opGet:
+			val, err := property(pop(), c.name(in.arg))
+			if err != nil {
+				return nil, runtimeError(c.tokens[in.tok], err)
+			}
+			stack = append(stack, val)
+		case opSetTarget:
+			o, err := propertyTarget(pop(), c.name(in.arg))
+			if err != nil {
+				return nil, runtimeError(c.tokens[in.tok], err)
+			}
+			stack = append(stack, o)
+		case opSet:
+			value := pop()
+			o, ok := pop().(*object)
+			if !ok {
+				return nil, errInvalidBytecode(ip-1, "property set without target")
+			}
+			if err := o.set(String(c.name(in.arg)), value); err != nil {
+				return nil, runtimeError(c.tokens[in.tok], err)
+			}
+			stack = append(stack, nil)
+		case opTransaction:
+			tx := env.lookupTransaction()
+			if tx == nil {
+				return nil, runtimeError(c.tokens[in.tok], errors.New("No transaction in the execution context"))
+			}
+			stack = append(stack, tx.object())
+		case opPrint:
+			if err := env.print(pop()); err != nil {
+				return nil, err
+			}
+		case opPushScope:
+			env = NewEnvironment(env)
+			scopes++
+		case opPopScope:
+			if scopes == 0 {
+				return nil, errInvalidBytecode(ip-1, "no scope to leave")
+			}
+			env = env.enclosing
+			scopes--
+		case opFunction:
+			f := c.constants[in.arg].(*compiledFunction)
+			if err := env.assign(f.name, f); err != nil {
+				return nil, err
+			}
+		case opContract:
+			proto := c.constants[in.arg].(*compiledContract)
+			contract := newContract(env, proto.name)
+			for _, f := range proto.functions {
+				contract.addFunction(f.name, f)
+			}
+			for i, field := range proto.fields {
+				init := proto.values[i]
+				err := contract.initField(env.exec, field, func() (Value, error) {
+					return run(init, contract.env, nil)
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+			if err := env.exec.track(contract, false); err != nil {
+				return nil, err
+			}
+			if err := env.assign(proto.name, contract); err != nil {
+				return nil, err
+			}
+			stack = append(stack, contract)
+		case opReturn:
+			//Only a non nil value leaves the function, a nil one ends the block holding the return
+			if val := pop(); val != nil {
+				return val, nil
+			}
+			if in.arg >= 0 {
+				ip = in.arg
+			}
+		case opIterInit:
+			items, err := iterationItems(pop())
+			if err != nil {
+				return nil, runtimeError(c.tokens[in.tok], err)
+			}
+			iterators = append(iterators, &iterator{items: items})
+		case opIterNext:
+			if len(iterators) == 0 {
+				return nil, errInvalidBytecode(ip-1, "no loop to iterate")
+			}
+			it := iterators[len(iterators)-1]
+			if it.next >= len(it.items) {
+				iterators = iterators[:len(iterators)-1]
+				ip = in.arg
+				continue
+			}
+			stack = append(stack, it.items[it.next])
+			it.next++
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,196 @@
+package uniris
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fibonacciCode = `
+function fibonacci(n) {
+    if n <= 1 {
+        return n
+    }
+    return fibonacci(n-2) + fibonacci(n-1)
+}
+fibonacci(20)
+`
+
+//runBoth executes a code with the tree walking interpreter and the virtual machine
+func runBoth(t *testing.T, code string) (*Result, error, *Result, error) {
+	res, err := Interpret(code, nil)
+	b, cerr := Compile(code)
+	if !assert.Nil(t, cerr) {
+		return res, err, nil, cerr
+	}
+	vmRes, vmErr := b.Run(nil)
+	return res, err, vmRes, vmErr
+}
+
+func TestVMSameOutputAsInterpreter(t *testing.T) {
+	programs := []string{
+		fibonacciCode,
+		"a = 1\na + 1\nb = [a]\nb",
+		"[1] + [2, 3]\n1 + 2 * 3 - 4 / 2\n7 % 3\n1.5 * 2\n1.5d + 1\n\"a\" + 1",
+		"-(2)\n!true\n1 < 2 and 2 < 1\nfalse or \"x\"\nfalse and x\n1 == 1.0",
+		"s = 0\nfor i = 0; i < 10; i = i + 1 {\n s = s + i\n}\ns",
+		"s = \"\"\nfor k in {a: 1, b: 2} {\n s = s + k\n}\nfor x in [1, 2, 3] {\n s = s + x\n}\ns",
+		"i = 0\nwhile i < 5 {\n i = i + 1\n}\ni",
+		"xs = [1, 2]\nxs[0] = 5\nxs\no = {a: 1}\no.b = o.a + 1\no\no[\"a\"]\n\"abc\"[1]",
+		"y = 0\nfunction none() {}\nfunction f(x) {\n if x > 0 {\n  return none()\n }\n y = x\n}\nf(1)\nf(0)\ny",
+		"function g() {\n for x in [1, 2, 3] {\n  if x == 2 {\n   return x\n  }\n }\n}\ng()",
+		"len([1, 2, 3])\nkeys({a: 1})\nsha256(\"abc\")",
+		"a = 0\nif false {\n 1\n} else {\n a = 2\n}\na",
+	}
+	for _, code := range programs {
+		res, err, vmRes, vmErr := runBoth(t, code)
+		assert.Nil(t, err)
+		assert.Nil(t, vmErr, code)
+		if vmRes != nil {
+			assert.Equal(t, res.Output, vmRes.Output, code)
+		}
+	}
+}
+
+func TestVMSameErrorsAsInterpreter(t *testing.T) {
+	programs := []string{
+		"1 - \"a\"",
+		"x + 1",
+		"a = 1\na()",
+		"-\"a\"",
+		"[1][3]",
+		"for x in 1 {}",
+		"o = {}\no.a.b = 1",
+		"function f(a) {}\nf()",
+		"9223372036854775807 + 1",
+	}
+	for _, code := range programs {
+		_, err, _, vmErr := runBoth(t, code)
+		assert.NotNil(t, err, code)
+		assert.Equal(t, err, vmErr, code)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	_, err := Compile("a = ")
+	assert.EqualError(t, err, "Parsing error at end of line 1, column 5 - Expected expression")
+}
+
+func TestTopLevelReturnRejected(t *testing.T) {
+	for _, code := range []string{"return 5", "if true {\n return 5\n}", "contract C {\n a = 1\n}\nreturn a"} {
+		_, err := Interpret(code, nil)
+		_, cerr := Compile(code)
+		assert.Error(t, err, code)
+		assert.Equal(t, err, cerr, code)
+	}
+	_, err := Interpret("return 5", nil)
+	assert.EqualError(t, err, "Parsing error at return of line 1, column 1 - Cannot return from top level code")
+
+	res, err, vmRes, vmErr := runBoth(t, "function f() {\n return 5\n}\nf()")
+	assert.Nil(t, err)
+	assert.Nil(t, vmErr)
+	assert.Equal(t, "5\n", res.Output)
+	assert.Equal(t, res.Output, vmRes.Output)
+}
+
+func TestVMContract(t *testing.T) {
+	b, err := Compile(apostilleContract)
+	assert.Nil(t, err)
+	res, err := b.Run(nil)
+	assert.Nil(t, err)
+
+	c := res.Contract
+	assert.Equal(t, []string{"isApostilled", "refugeeID"}, c.Fields())
+	_, err = c.Call("setApostille", String("123"))
+	assert.Nil(t, err)
+	_, err = c.Call("setApostille", String("456"))
+	assert.Nil(t, err)
+	id, err := c.Call("getRefugeeID")
+	assert.Nil(t, err)
+	assert.Equal(t, String("123"), id)
+}
+
+func TestVMRunsSeveralTimes(t *testing.T) {
+	b, err := Compile("a = a + 1\na")
+	assert.Nil(t, err)
+
+	env := NewEnvironment(nil)
+	env.Set("a", Int(0))
+	for _, expected := range []string{"1\n", "2\n"} {
+		res, err := b.Run(env)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, res.Output)
+	}
+}
+
+func TestVMOutOfGas(t *testing.T) {
+	b, err := Compile("while true {}")
+	assert.Nil(t, err)
+
+	env := NewEnvironment(nil)
+	env.SetGasLimit(1000)
+	_, err = b.Run(env)
+	var outOfGas *OutOfGasError
+	assert.True(t, errors.As(err, &outOfGas))
+	assert.Equal(t, uint64(1000), outOfGas.Limit)
+}
+
+func TestVMGasUsed(t *testing.T) {
+	b, err := Compile("a = 1 + 2")
+	assert.Nil(t, err)
+	res, err := b.Run(nil)
+	assert.Nil(t, err)
+	//2 constants, the addition, the assignation and the globals environment it walks through
+	assert.Equal(t, uint64(7), res.GasUsed)
+}
+
+func TestVMContextTimeout(t *testing.T) {
+	b, err := Compile("while true {}")
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = b.RunContext(ctx, nil)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestVMDeterministic(t *testing.T) {
+	b, err := Compile("now()")
+	assert.Nil(t, err)
+
+	env := NewEnvironment(nil)
+	env.SetDeterministic(true)
+	env.SetClock(func() int64 { return 1000 })
+	res, err := b.Run(env)
+	assert.Nil(t, err)
+	assert.Equal(t, "1000\n", res.Output)
+}
+
+func BenchmarkFibonacciInterpreter(b *testing.B) {
+	stmts, err := parseCode(fibonacciCode)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := execute(context.Background(), stmts, nil, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFibonacciVM(b *testing.B) {
+	code, err := Compile(fibonacciCode)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := code.Run(nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}